@@ -0,0 +1,206 @@
+package classify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"line-chatbot-golang-langchain/llm"
+)
+
+// AllowedTypes are the DISC types the classifier may return: the four
+// pure styles and every two-letter blend, primary letter first.
+var AllowedTypes = func() []string {
+	letters := []string{"D", "I", "S", "C"}
+	types := append([]string(nil), letters...)
+	for _, a := range letters {
+		for _, b := range letters {
+			if a != b {
+				types = append(types, a+b)
+			}
+		}
+	}
+	return types
+}()
+
+// ResponseSchema is sent with every request so providers that support
+// structured output can only answer with a valid result.
+var ResponseSchema = &llm.Schema{
+	Type: "object",
+	Properties: map[string]*llm.Schema{
+		"model": {
+			Type:        "string",
+			Description: "ประเภท DISC ที่เหมาะสม",
+			Enum:        AllowedTypes,
+		},
+		"description": {
+			Type:        "string",
+			Description: "คำอธิบายเหตุผลที่เลือกประเภทนี้",
+		},
+	},
+	Required:             []string{"model", "description"},
+	AdditionalProperties: new(bool),
+}
+
+// Result is a validated classification.
+type Result struct {
+	Model       string `json:"model"`
+	Description string `json:"description"`
+	// LLMModel names the model that produced the result.
+	LLMModel string `json:"llmModel"`
+	Attempts int    `json:"attempts"`
+}
+
+var ErrInvalidOutput = errors.New("invalid classifier output")
+
+// Retriever returns knowledge-base passages relevant to a query.
+type Retriever func(ctx context.Context, query string) ([]string, error)
+
+type Classifier struct {
+	LLM      llm.LLM
+	Retrieve Retriever
+	// MaxAttempts bounds the retries on invalid output; 3 when zero.
+	MaxAttempts int
+}
+
+// Classify asks the LLM for a DISC type grounded in retrieved passages.
+// Output that does not match the schema is repaired when possible and
+// otherwise retried with the validation error fed back to the model.
+func (c *Classifier) Classify(ctx context.Context, userText string) (Result, error) {
+	var passages []string
+	if c.Retrieve != nil {
+		docs, err := c.Retrieve(ctx, userText)
+		if err != nil {
+			log.Println("⚠️ Retrieval failed, classifying without context:", err)
+		} else {
+			passages = docs
+		}
+	}
+
+	attempts := c.MaxAttempts
+	if attempts <= 0 {
+		attempts = 3
+	}
+
+	prompt := buildPrompt(userText, passages)
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		raw, err := c.LLM.GenerateStructured(ctx, prompt, ResponseSchema, llm.WithTemperature(0.2))
+		if err != nil {
+			return Result{}, err
+		}
+
+		result, err := Parse(raw)
+		if err == nil {
+			result.LLMModel = c.LLM.Model()
+			result.Attempts = attempt
+			return result, nil
+		}
+
+		lastErr = err
+		log.Printf("⚠️ Classifier attempt %d returned invalid output: %v", attempt, err)
+		prompt = buildPrompt(userText, passages) + fmt.Sprintf(`
+
+	คำตอบก่อนหน้าไม่ถูกต้อง (%v) กรุณาตอบเป็น JSON ตาม schema เท่านั้น โดย "model" ต้องเป็นหนึ่งใน %s`, err, strings.Join(AllowedTypes, ", "))
+	}
+	return Result{}, fmt.Errorf("classifier gave up after %d attempts: %w", attempts, lastErr)
+}
+
+// Parse validates raw model output. It tolerates Markdown fences and prose
+// around the JSON object and labels like "D (Dominance)", but rejects any
+// type outside AllowedTypes and an empty description.
+func Parse(raw string) (Result, error) {
+	object, ok := extractObject(raw)
+	if !ok {
+		return Result{}, fmt.Errorf("%w: no JSON object", ErrInvalidOutput)
+	}
+
+	var out struct {
+		Model       string `json:"model"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal([]byte(object), &out); err != nil {
+		return Result{}, fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+	}
+
+	model, ok := NormalizeType(out.Model)
+	if !ok {
+		return Result{}, fmt.Errorf("%w: model %q is not a DISC type", ErrInvalidOutput, out.Model)
+	}
+	description := strings.TrimSpace(out.Description)
+	if description == "" {
+		return Result{}, fmt.Errorf("%w: empty description", ErrInvalidOutput)
+	}
+	return Result{Model: model, Description: description}, nil
+}
+
+// NormalizeType maps labels such as "di", "D/I" or "C (Conscientiousness)"
+// onto AllowedTypes.
+func NormalizeType(label string) (string, bool) {
+	var letters strings.Builder
+scan:
+	for _, r := range strings.ToUpper(strings.TrimSpace(label)) {
+		switch r {
+		case 'D', 'I', 'S', 'C':
+			letters.WriteRune(r)
+		case '/', '-', '+', ' ':
+		default:
+			break scan
+		}
+	}
+	candidate := letters.String()
+	for _, allowed := range AllowedTypes {
+		if candidate == allowed {
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// extractObject returns the first balanced {...} block in s.
+func extractObject(s string) (string, bool) {
+	start := strings.Index(s, "{")
+	if start < 0 {
+		return "", false
+	}
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(s); i++ {
+		ch := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case ch == '\\' && inString:
+			escaped = true
+		case ch == '"':
+			inString = !inString
+		case ch == '{' && !inString:
+			depth++
+		case ch == '}' && !inString:
+			depth--
+			if depth == 0 {
+				return s[start : i+1], true
+			}
+		}
+	}
+	return "", false
+}
+
+func buildPrompt(userText string, passages []string) string {
+	return fmt.Sprintf(`
+	คุณคือผู้เชี่ยวชาญด้าน DISC Model ซึ่งแบ่งบุคลิกภาพออกเป็น 4 กลุ่ม คือ D (Dominance), I (Influence), S (Steadiness), C (Conscientiousness)
+	พิจารณาบุคลิกภาพต่อไปนี้:
+	"%s"
+
+	และจากข้อมูล DISC ด้านล่าง:
+	%s
+
+	ช่วยระบุว่าบุคคลนี้น่าจะตรงกับ DISC ประเภทใดมากที่สุด และให้คำอธิบายอย่างกระชับ
+	หากมีคะแนน DISC ที่คำนวณแล้ว ให้ใช้ประเภทตามคะแนนนั้น และอธิบายโดยอ้างอิงคะแนน
+	ตอบเป็น JSON เท่านั้น: {"model": "<หนึ่งใน %s>", "description": "<คำอธิบาย>"}
+`, userText, strings.Join(passages, "\n\n"), strings.Join(AllowedTypes, ", "))
+}
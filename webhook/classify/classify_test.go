@@ -0,0 +1,179 @@
+package classify
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"line-chatbot-golang-langchain/llm"
+)
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		raw         string
+		model       string
+		description string
+		wantErr     bool
+	}{
+		{
+			name:        "plain JSON",
+			raw:         `{"model": "D", "description": "มุ่งผลลัพธ์"}`,
+			model:       "D",
+			description: "มุ่งผลลัพธ์",
+		},
+		{
+			name:        "code fence",
+			raw:         "```json\n{\"model\": \"SC\", \"description\": \"รอบคอบ\"}\n```",
+			model:       "SC",
+			description: "รอบคอบ",
+		},
+		{
+			name:        "prose around the object",
+			raw:         `ผลการวิเคราะห์: {"model": "i", "description": "  ร่าเริง {ชอบคน}  "} หวังว่าจะช่วยได้`,
+			model:       "I",
+			description: "ร่าเริง {ชอบคน}",
+		},
+		{
+			name:        "braces and quotes inside strings",
+			raw:         `{"model": "C (Conscientiousness)", "description": "ใช้ \"}\" อย่างระวัง"} {"model": "D"}`,
+			model:       "C",
+			description: `ใช้ "}" อย่างระวัง`,
+		},
+		{name: "unknown type", raw: `{"model": "X", "description": "?"}`, wantErr: true},
+		{name: "same letter twice", raw: `{"model": "DD", "description": "?"}`, wantErr: true},
+		{name: "three letters", raw: `{"model": "DIS", "description": "?"}`, wantErr: true},
+		{name: "empty description", raw: `{"model": "D", "description": "  "}`, wantErr: true},
+		{name: "no object", raw: `D`, wantErr: true},
+		{name: "unbalanced object", raw: `{"model": "D", "description": "x"`, wantErr: true},
+		{name: "wrong field type", raw: `{"model": 1, "description": "x"}`, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidOutput) {
+					t.Errorf("Parse = %+v, %v, want ErrInvalidOutput", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Model != tt.model || got.Description != tt.description {
+				t.Errorf("Parse = %q %q, want %q %q", got.Model, got.Description, tt.model, tt.description)
+			}
+		})
+	}
+}
+
+func TestNormalizeType(t *testing.T) {
+	for _, tt := range []struct {
+		label string
+		want  string
+		ok    bool
+	}{
+		{label: "D", want: "D", ok: true},
+		{label: " di ", want: "DI", ok: true},
+		{label: "D/I", want: "DI", ok: true},
+		{label: "S-C", want: "SC", ok: true},
+		{label: "I + S", want: "IS", ok: true},
+		{label: "C (Conscientiousness)", want: "C", ok: true},
+		{label: "DI (Dominance/Influence)", want: "DI", ok: true},
+		{label: "CD", want: "CD", ok: true},
+		{label: ""},
+		{label: "X"},
+		{label: "Dominance", want: "D", ok: true},
+		{label: "Xavier"},
+		{label: "II"},
+		{label: "DISC"},
+	} {
+		got, ok := NormalizeType(tt.label)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("NormalizeType(%q) = %q, %t, want %q, %t", tt.label, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestExtractObject(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		want string
+		ok   bool
+	}{
+		{s: `x {"a": {"b": 1}} y {"c": 2}`, want: `{"a": {"b": 1}}`, ok: true},
+		{s: `{"a": "\\"}`, want: `{"a": "\\"}`, ok: true},
+		{s: `{"a": "}\"{"}`, want: `{"a": "}\"{"}`, ok: true},
+		{s: `no braces`},
+		{s: `{"a": 1`},
+	} {
+		got, ok := extractObject(tt.s)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("extractObject(%q) = %q, %t, want %q, %t", tt.s, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestClassifyRetriesInvalidOutput(t *testing.T) {
+	fake := llm.NewFake(
+		llm.FakeReply{Text: `{"model": "ZD", "description": "x"}`},
+		llm.FakeReply{Text: `{"model": "DI", "description": "กล้าตัดสินใจและชอบคน"}`},
+	)
+	c := &Classifier{
+		LLM: fake,
+		Retrieve: func(ctx context.Context, query string) ([]string, error) {
+			return []string{"passage about D"}, nil
+		},
+	}
+
+	got, err := c.Classify(context.Background(), "ชอบนำ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Model != "DI" || got.Attempts != 2 || got.LLMModel != "fake" {
+		t.Errorf("Classify = %+v, want DI on attempt 2 from fake", got)
+	}
+
+	prompts := fake.Prompts()
+	if len(prompts) != 2 {
+		t.Fatalf("%d prompts, want 2", len(prompts))
+	}
+	if !strings.Contains(prompts[0], "passage about D") {
+		t.Error("retrieved passage missing from the prompt")
+	}
+	if !strings.Contains(prompts[1], "คำตอบก่อนหน้าไม่ถูกต้อง") || !strings.Contains(prompts[1], `"ZD"`) {
+		t.Errorf("retry prompt does not explain the error: %q", prompts[1])
+	}
+}
+
+func TestClassifyGivesUp(t *testing.T) {
+	fake := llm.NewFake()
+	fake.Default = &llm.FakeReply{Text: `{"model": "X", "description": "x"}`}
+	c := &Classifier{LLM: fake, MaxAttempts: 4}
+
+	_, err := c.Classify(context.Background(), "ชอบนำ")
+	if !errors.Is(err, ErrInvalidOutput) {
+		t.Fatalf("Classify = %v, want ErrInvalidOutput", err)
+	}
+	if n := len(fake.Prompts()); n != 4 {
+		t.Errorf("%d attempts, want 4", n)
+	}
+}
+
+func TestClassifyLLMErrorIsNotRetried(t *testing.T) {
+	unavailable := errors.New("unavailable")
+	fake := llm.NewFake(llm.FakeReply{Err: unavailable})
+	c := &Classifier{
+		LLM: fake,
+		Retrieve: func(ctx context.Context, query string) ([]string, error) {
+			return nil, errors.New("search down")
+		},
+	}
+
+	if _, err := c.Classify(context.Background(), "ชอบนำ"); !errors.Is(err, unavailable) {
+		t.Fatalf("Classify = %v, want the LLM error", err)
+	}
+	if n := len(fake.Prompts()); n != 1 {
+		t.Errorf("%d attempts, want 1", n)
+	}
+}
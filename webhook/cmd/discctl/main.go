@@ -0,0 +1,509 @@
+// Command discctl manages the DISC knowledge base and tries the
+// classification pipeline without going through LINE.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"line-chatbot-golang-langchain/classify"
+	"line-chatbot-golang-langchain/ingest"
+	"line-chatbot-golang-langchain/llm"
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/service"
+	"line-chatbot-golang-langchain/utils"
+	"line-chatbot-golang-langchain/vectorstore"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+const usage = `discctl manages the DISC knowledge base.
+
+Usage:
+  discctl ingest   [-manifest file] [-force] [-prune]
+  discctl reindex  [-manifest file]
+  discctl index    create|drop|status
+  discctl index    swap [-manifest file]
+  discctl index    purge <generation>
+  discctl search   [-k 5] [-threshold 0] [-filter key=value] <query>
+  discctl classify <answers-file>
+  discctl export   [-o file] [-vectors]
+
+Settings are read from the environment and .env, as for the bot.
+`
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "help" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := godotenv.Load(); err != nil && !errors.Is(err, os.ErrNotExist) {
+		fmt.Fprintln(os.Stderr, "discctl: .env:", err)
+	}
+	if os.Getenv("DISCCTL_VERBOSE") == "" {
+		// The shared packages log every step; keep the output to progress.
+		log.SetOutput(io.Discard)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := run(ctx, os.Args[1], os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, "❌ discctl:", err)
+		stop()
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, command string, args []string) error {
+	switch command {
+	case "ingest":
+		return cmdIngest(ctx, args, false)
+	case "reindex":
+		return cmdIngest(ctx, args, true)
+	case "index":
+		return cmdIndex(ctx, args)
+	case "search":
+		return cmdSearch(ctx, args)
+	case "classify":
+		return cmdClassify(ctx, args)
+	case "export":
+		return cmdExport(ctx, args)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		return fmt.Errorf("unknown command %q", command)
+	}
+}
+
+// setup opens what a command needs. MongoDB is skipped when neither the
+// vector store nor the embedding cache live there, so the knowledge base
+// can be built fully offline.
+func setup(ctx context.Context, withLLM bool) (*utils.Knowledge, func(), error) {
+	var closers []func()
+	cleanup := func() {
+		for i := len(closers) - 1; i >= 0; i-- {
+			closers[i]()
+		}
+	}
+
+	var db *mongo.Database
+	if needsMongo() {
+		m, err := utils.InitMongo(ctx)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("connect to MongoDB: %w", err)
+		}
+		closers = append(closers, m.Close)
+		db = m.DB
+	}
+	embedder, err := utils.NewEmbedder(ctx, db)
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("create embedder: %w", err)
+	}
+	var llmClient llm.LLM
+	if withLLM {
+		llmClient, err = utils.NewLLM(ctx)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("create LLM client: %w", err)
+		}
+		closers = append(closers, func() { utils.CloseLLM(llmClient) })
+	}
+	kb, err := utils.OpenKnowledge(ctx, db, embedder, llmClient)
+	if err != nil {
+		return nil, cleanup, fmt.Errorf("open vector store: %w", err)
+	}
+	return kb, cleanup, nil
+}
+
+func needsMongo() bool {
+	if os.Getenv("VECTOR_STORE") != "memory" {
+		return true
+	}
+	cache := os.Getenv("EMBEDDING_CACHE")
+	return cache != "memory" && cache != "off"
+}
+
+func cmdIngest(ctx context.Context, args []string, reindex bool) error {
+	name := "ingest"
+	if reindex {
+		name = "reindex"
+	}
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	manifest := fs.String("manifest", utils.KnowledgeManifest(), "manifest of sources")
+	force := fs.Bool("force", false, "re-embed sources even when unchanged")
+	prune := fs.Bool("prune", false, "remove sources no longer in the manifest")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if reindex {
+		// Re-embed every source and drop the ones no longer listed.
+		*force, *prune = true, true
+	}
+
+	kb, cleanup, err := setup(ctx, false)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("📚 %s %s\n", name, *manifest)
+	report, err := kb.IngestKnowledge(ctx, utils.IngestOptions{
+		Manifest: *manifest,
+		Force:    *force,
+		Prune:    *prune,
+		OnSource: printSource(),
+	})
+	if err != nil {
+		return err
+	}
+
+	total, err := kb.Store.Count(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("📦 %d chunks in the vector store\n", total)
+	if failed := report.Failed(); failed > 0 {
+		return fmt.Errorf("%d of %d sources failed", failed, len(report.Sources))
+	}
+	return nil
+}
+
+// printSource prints one numbered progress line per ingested source.
+func printSource() func(ingest.SourceReport) {
+	n := 0
+	return func(sr ingest.SourceReport) {
+		n++
+		mark := "✅"
+		if sr.Status == ingest.StatusFailed {
+			mark = "❌"
+		}
+		fmt.Printf("%s [%d] %-40s %-9s %4d chunks", mark, n, sr.ID, sr.Status, sr.Chunks)
+		if sr.Skipped > 0 {
+			fmt.Printf(", %d duplicates skipped", sr.Skipped)
+		}
+		if sr.Deleted > 0 {
+			fmt.Printf(", %d old chunks removed", sr.Deleted)
+		}
+		if sr.Err != nil {
+			fmt.Printf(": %v", sr.Err)
+		}
+		fmt.Println()
+	}
+}
+
+func cmdIndex(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return errors.New("usage: discctl index create|drop|status|swap|purge")
+	}
+	kb, cleanup, err := setup(ctx, false)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	gen := kb.Generation
+	if gen == nil {
+		return errors.New("vector indexes only exist for VECTOR_STORE=mongo")
+	}
+	embedder := kb.Embedder
+
+	switch args[0] {
+	case "create":
+		fmt.Printf("⚙️ Ensuring index %s on %s (%d dimensions, %s) and waiting until it is queryable...\n",
+			gen.Index, gen.Collection, embedder.Dimensions(), embedder.Similarity())
+		action, err := kb.EnsureVectorIndex(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Vector index %s\n", action)
+	case "drop":
+		if err := kb.DropVectorIndex(ctx); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️ Vector index %s on %s dropped\n", gen.Index, gen.Collection)
+	case "status":
+		return printIndexStatus(ctx, kb, gen.ID)
+	case "swap":
+		fs := flag.NewFlagSet("index swap", flag.ContinueOnError)
+		manifest := fs.String("manifest", utils.KnowledgeManifest(), "manifest of sources")
+		if err := fs.Parse(args[1:]); err != nil {
+			return err
+		}
+		if gen.State == vectorstore.GenerationActive {
+			fmt.Printf("✅ Generation %s (%s) is already live\n", gen.ID, gen.Model)
+			return nil
+		}
+		fmt.Printf("🔀 Building generation %s for %s in %s...\n", gen.ID, gen.Model, gen.Collection)
+		_, err := kb.SwapVectorGeneration(ctx, utils.IngestOptions{
+			Manifest: *manifest,
+			Force:    true,
+			Prune:    true,
+			OnSource: printSource(),
+		})
+		if err != nil {
+			return err
+		}
+		fmt.Printf("✅ Generation %s is live. Redeploy the bot with EMBEDDING_MODEL=%s, then purge the retired generation.\n", gen.ID, gen.Model)
+	case "purge":
+		if len(args) != 2 {
+			return errors.New("usage: discctl index purge <generation>")
+		}
+		if err := kb.PurgeVectorGeneration(ctx, args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("🗑️ Generation %s purged\n", args[1])
+	default:
+		return fmt.Errorf("unknown index command %q", args[0])
+	}
+	return nil
+}
+
+func printIndexStatus(ctx context.Context, kb *utils.Knowledge, current string) error {
+	gens, err := kb.VectorGenerations(ctx)
+	if err != nil {
+		return err
+	}
+	if len(gens) == 0 {
+		fmt.Println("∅ No generations registered; run `discctl index create`")
+		return nil
+	}
+	for _, gen := range gens {
+		mark := " "
+		if gen.ID == current {
+			mark = "*"
+		}
+		fmt.Printf("%s %s  %-8s  %s  %d dims  %s  %s\n", mark, gen.ID, gen.State, gen.Model, gen.Dimensions, gen.Similarity, gen.Collection)
+		status, err := kb.VectorIndexStatus(ctx, gen)
+		switch {
+		case err != nil:
+			fmt.Printf("    index %s: %v\n", gen.Index, err)
+		case status == nil:
+			fmt.Printf("    index %s: missing\n", gen.Index)
+		default:
+			fmt.Printf("    index %s: %s, queryable=%v, %d fields\n", status.Name, status.Status, status.Queryable, len(status.Definition.Fields))
+		}
+	}
+	return nil
+}
+
+func cmdSearch(ctx context.Context, args []string) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	k := fs.Int("k", 5, "number of chunks")
+	threshold := fs.Float64("threshold", 0, "minimum score in [0, 1]")
+	var filters filterFlag
+	fs.Var(&filters, "filter", "metadata key=value, repeatable; type non-string values as int:3, float:0.5 or bool:true")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	query := strings.Join(fs.Args(), " ")
+	if query == "" {
+		return errors.New("usage: discctl search [flags] <query>")
+	}
+
+	kb, cleanup, err := setup(ctx, false)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	opts := []vectorstore.SearchOption{vectorstore.WithThreshold(float32(*threshold))}
+	if len(filters) > 0 {
+		opts = append(opts, vectorstore.WithFilter(filters))
+	}
+	docs, err := kb.Store.Search(ctx, query, *k, opts...)
+	if err != nil {
+		return err
+	}
+	if len(docs) == 0 {
+		fmt.Println("∅ No matching chunks")
+		return nil
+	}
+	for i, doc := range docs {
+		fmt.Printf("%d. score %.4f  %s\n", i+1, doc.Score, formatMetadata(doc.Metadata))
+		fmt.Printf("   %s\n\n", strings.ReplaceAll(truncate(doc.PageContent, 400), "\n", "\n   "))
+	}
+	return nil
+}
+
+func cmdClassify(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: discctl classify <answers-file>")
+	}
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+	// Same body the LIFF app posts to /submit-answer.
+	var req models.AnswerRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		return fmt.Errorf("decode %s: %w", args[0], err)
+	}
+
+	kb, cleanup, err := setup(ctx, true)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("🧮 Scoring and classifying...")
+	// Evaluate neither verifies nor saves, so only the LLM is wired in. It
+	// falls back to a stock description when the LLM fails; the error is
+	// kept so the command still exits non-zero.
+	var llmErr error
+	svc := &service.SubmissionService{
+		Classify: func(ctx context.Context, prompt string) (classify.Result, error) {
+			result, err := kb.ClassifyDisc(ctx, prompt)
+			llmErr = err
+			return result, err
+		},
+	}
+	result, err := svc.Evaluate(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Style:       %s (confidence %.2f)\n", result.Score.Style, result.Score.Confidence)
+	fmt.Printf("Scores:      %s\n", service.FormatScores(result.Score))
+	if result.LLMModel != "" {
+		fmt.Printf("LLM:         %s said %s\n", result.LLMModel, result.LLMType)
+	} else {
+		fmt.Println("LLM:         unavailable, stock description used")
+	}
+	fmt.Printf("Description: %s\n", result.Description)
+	if llmErr != nil {
+		return fmt.Errorf("LLM classification failed: %w", llmErr)
+	}
+	return nil
+}
+
+func cmdExport(ctx context.Context, args []string) (err error) {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("o", "-", "output file, - for stdout")
+	withVectors := fs.Bool("vectors", false, "include the embedding vectors")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	kb, cleanup, err := setup(ctx, false)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	exporter, ok := kb.Store.(vectorstore.Exporter)
+	if !ok {
+		return errors.New("the configured vector store cannot be exported")
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "-" {
+		f, createErr := os.Create(*output)
+		if createErr != nil {
+			return createErr
+		}
+		// A failed close can mean the last writes never reached the disk.
+		defer func() {
+			if closeErr := f.Close(); closeErr != nil && err == nil {
+				err = fmt.Errorf("close %s: %w", *output, closeErr)
+			}
+		}()
+		w = f
+	}
+
+	// One JSON object per line, so large exports can be streamed.
+	enc := json.NewEncoder(w)
+	n := 0
+	err = exporter.Each(ctx, func(c vectorstore.Chunk) error {
+		if !*withVectors {
+			c.Vector = nil
+		}
+		n++
+		if n%100 == 0 {
+			fmt.Fprintf(os.Stderr, "… %d chunks\n", n)
+		}
+		return enc.Encode(c)
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✅ Exported %d chunks\n", n)
+	return nil
+}
+
+// filterFlag collects repeated -filter key=value flags. Values are strings
+// unless typed as int:3, float:0.5 or bool:true, because both stores only
+// match metadata of the same type: "3" does not match a chunk number 3.
+type filterFlag map[string]any
+
+func (f *filterFlag) String() string {
+	return fmt.Sprint(map[string]any(*f))
+}
+
+func (f *filterFlag) Set(value string) error {
+	key, val, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("filter %q is not key=value", value)
+	}
+	typed, err := filterValue(val)
+	if err != nil {
+		return fmt.Errorf("filter %q: %w", value, err)
+	}
+	if *f == nil {
+		*f = filterFlag{}
+	}
+	(*f)[key] = typed
+	return nil
+}
+
+// filterValue parses a typed filter value. An unknown prefix, as in
+// "https://...", is part of a string value.
+func filterValue(val string) (any, error) {
+	kind, raw, ok := strings.Cut(val, ":")
+	if !ok {
+		return val, nil
+	}
+	switch kind {
+	case "int":
+		return strconv.ParseInt(raw, 10, 64)
+	case "float":
+		return strconv.ParseFloat(raw, 64)
+	case "bool":
+		return strconv.ParseBool(raw)
+	case "string":
+		return raw, nil
+	}
+	return val, nil
+}
+
+func formatMetadata(metadata map[string]any) string {
+	keys := make([]string, 0, len(metadata))
+	for key := range metadata {
+		if key == ingest.MetadataHash {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		parts = append(parts, fmt.Sprintf("%s=%v", key, metadata[key]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func truncate(text string, max int) string {
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max]) + "…"
+}
@@ -0,0 +1,268 @@
+package coaching
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"line-chatbot-golang-langchain/llm"
+	"line-chatbot-golang-langchain/scoring"
+	"line-chatbot-golang-langchain/team"
+)
+
+// PromptVersion is part of every cached report's fingerprint; bump it when
+// the prompt or schema changes so old reports are regenerated.
+const PromptVersion = "1"
+
+// ResponseSchema is the shape of a coaching report. Members are referred to
+// by their number in the prompt, never by LINE user ID.
+var ResponseSchema = &llm.Schema{
+	Type: "object",
+	Properties: map[string]*llm.Schema{
+		"summary": {
+			Type:        "string",
+			Description: "ภาพรวมของทีมใน 1-2 ประโยค",
+		},
+		"communication": {
+			Type:        "array",
+			Description: "แนวทางการสื่อสารที่เหมาะกับทีมนี้",
+			Items:       &llm.Schema{Type: "string"},
+		},
+		"meetings": {
+			Type:        "array",
+			Description: "แนวทางการประชุมที่เหมาะกับทีมนี้",
+			Items:       &llm.Schema{Type: "string"},
+		},
+		"roles": {
+			Type:        "array",
+			Description: "บทบาทที่แนะนำให้สมาชิกแต่ละคน",
+			Items: &llm.Schema{
+				Type: "object",
+				Properties: map[string]*llm.Schema{
+					"member": {Type: "integer", Description: "หมายเลขสมาชิกตามรายชื่อ"},
+					"role":   {Type: "string"},
+					"reason": {Type: "string"},
+				},
+				Required:             []string{"member", "role", "reason"},
+				AdditionalProperties: new(bool),
+			},
+		},
+	},
+	Required:             []string{"summary", "communication", "meetings", "roles"},
+	AdditionalProperties: new(bool),
+}
+
+// Report is the coaching advice for one team.
+type Report struct {
+	Summary       string   `json:"summary" bson:"summary"`
+	Communication []string `json:"communication" bson:"communication"`
+	Meetings      []string `json:"meetings" bson:"meetings"`
+	Roles         []Role   `json:"roles" bson:"roles"`
+	// LLMModel names the model that wrote the report.
+	LLMModel string `json:"llmModel" bson:"llmModel"`
+}
+
+// Role is a suggested role for one member.
+type Role struct {
+	UserID string `json:"userId" bson:"userId"`
+	Style  string `json:"style" bson:"style"`
+	Role   string `json:"role" bson:"role"`
+	Reason string `json:"reason" bson:"reason"`
+}
+
+var ErrInvalidOutput = errors.New("invalid coaching output")
+
+// Retriever returns knowledge-base passages relevant to a query.
+type Retriever func(ctx context.Context, query string) ([]string, error)
+
+type Coach struct {
+	LLM      llm.LLM
+	Retrieve Retriever
+	// MaxAttempts bounds the retries on invalid output; 2 when zero.
+	MaxAttempts int
+}
+
+// Advise asks the LLM for coaching grounded in the team analysis and in
+// passages retrieved for the team's makeup. Members must be the ones the
+// analysis was computed from.
+func (c *Coach) Advise(ctx context.Context, analysis team.Report, members []team.Member) (Report, error) {
+	if len(members) == 0 {
+		return Report{}, errors.New("no members to coach")
+	}
+
+	var passages []string
+	if c.Retrieve != nil {
+		docs, err := c.Retrieve(ctx, Query(analysis))
+		if err != nil {
+			log.Println("⚠️ Retrieval failed, coaching without context:", err)
+		} else {
+			passages = docs
+		}
+	}
+
+	attempts := c.MaxAttempts
+	if attempts <= 0 {
+		attempts = 2
+	}
+
+	prompt := buildPrompt(analysis, members, passages)
+	var lastErr error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		raw, err := c.LLM.GenerateStructured(ctx, prompt, ResponseSchema, llm.WithTemperature(0.4))
+		if err != nil {
+			return Report{}, err
+		}
+
+		report, err := Parse(raw, members)
+		if err == nil {
+			report.LLMModel = c.LLM.Model()
+			return report, nil
+		}
+
+		lastErr = err
+		log.Printf("⚠️ Coaching attempt %d returned invalid output: %v", attempt, err)
+		prompt = buildPrompt(analysis, members, passages) + fmt.Sprintf(`
+
+	คำตอบก่อนหน้าไม่ถูกต้อง (%v) กรุณาตอบเป็น JSON ตาม schema เท่านั้น โดย "member" ต้องเป็นเลข 1 ถึง %d`, err, len(members))
+	}
+	return Report{}, fmt.Errorf("coach gave up after %d attempts: %w", attempts, lastErr)
+}
+
+// Query is the knowledge-base search for a team: its dominant and missing
+// styles, or the four styles working together when it is balanced.
+func Query(analysis team.Report) string {
+	var parts []string
+	for _, style := range analysis.Dominant {
+		parts = append(parts, "จุดแข็งและจุดอ่อนของคนประเภท "+style.Style)
+	}
+	for _, style := range analysis.Missing {
+		parts = append(parts, "ทีมที่ขาดคนประเภท "+style.Style)
+	}
+	if len(parts) == 0 {
+		return "การทำงานร่วมกันและการสื่อสารของคนประเภท D I S C ในทีม"
+	}
+	return strings.Join(parts, " ") + " การทำงานร่วมกันในทีม"
+}
+
+// Parse validates raw model output and maps member numbers back to the
+// members they stand for.
+func Parse(raw string, members []team.Member) (Report, error) {
+	start, end := strings.Index(raw, "{"), strings.LastIndex(raw, "}")
+	if start < 0 || end < start {
+		return Report{}, fmt.Errorf("%w: no JSON object", ErrInvalidOutput)
+	}
+
+	var out struct {
+		Summary       string   `json:"summary"`
+		Communication []string `json:"communication"`
+		Meetings      []string `json:"meetings"`
+		Roles         []struct {
+			Member int    `json:"member"`
+			Role   string `json:"role"`
+			Reason string `json:"reason"`
+		} `json:"roles"`
+	}
+	if err := json.Unmarshal([]byte(raw[start:end+1]), &out); err != nil {
+		return Report{}, fmt.Errorf("%w: %v", ErrInvalidOutput, err)
+	}
+
+	report := Report{
+		Summary:       strings.TrimSpace(out.Summary),
+		Communication: nonEmpty(out.Communication),
+		Meetings:      nonEmpty(out.Meetings),
+	}
+	if len(report.Communication) == 0 || len(report.Meetings) == 0 {
+		return Report{}, fmt.Errorf("%w: missing communication or meeting guidelines", ErrInvalidOutput)
+	}
+
+	seen := map[int]bool{}
+	for _, role := range out.Roles {
+		if role.Member < 1 || role.Member > len(members) {
+			return Report{}, fmt.Errorf("%w: unknown member %d", ErrInvalidOutput, role.Member)
+		}
+		if seen[role.Member] || strings.TrimSpace(role.Role) == "" {
+			continue
+		}
+		seen[role.Member] = true
+		m := members[role.Member-1]
+		report.Roles = append(report.Roles, Role{
+			UserID: m.UserID,
+			Style:  m.Style,
+			Role:   strings.TrimSpace(role.Role),
+			Reason: strings.TrimSpace(role.Reason),
+		})
+	}
+	return report, nil
+}
+
+func nonEmpty(items []string) []string {
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func buildPrompt(analysis team.Report, members []team.Member, passages []string) string {
+	var roster strings.Builder
+	for i, m := range members {
+		fmt.Fprintf(&roster, "%d. ประเภท %s", i+1, m.Style)
+		if len(m.Scores) > 0 {
+			fmt.Fprintf(&roster, " (%s)", formatShares(m.Scores))
+		}
+		roster.WriteString("\n")
+	}
+
+	var styles []string
+	for _, style := range analysis.Dominant {
+		styles = append(styles, fmt.Sprintf("สไตล์เด่น %s %.0f%%", style.Style, style.Share))
+	}
+	for _, style := range analysis.Missing {
+		styles = append(styles, "ไม่มีสมาชิกประเภท "+style.Style)
+	}
+	var frictions []string
+	for _, friction := range analysis.Frictions {
+		frictions = append(frictions, fmt.Sprintf("%s: %s", friction.Style, friction.Note))
+	}
+
+	return fmt.Sprintf(`
+	คุณคือโค้ชทีมที่เชี่ยวชาญ DISC Model ซึ่งแบ่งบุคลิกภาพออกเป็น 4 กลุ่ม คือ D (Dominance), I (Influence), S (Steadiness), C (Conscientiousness)
+
+	ทีมนี้มีสมาชิก %d คน:
+	%s
+	สัดส่วนเฉลี่ยของทีม: %s
+	ความสมดุลของทีม: %.0f%%
+	%s
+	จุดที่อาจเกิดแรงเสียดทาน: %s
+
+	ข้อมูล DISC จากฐานความรู้:
+	%s
+
+	ให้คำแนะนำที่เจาะจงกับทีมนี้ ไม่ใช่คำแนะนำทั่วไป โดยอ้างอิงข้อมูลด้านบน
+	- communication: แนวทางการสื่อสาร 3-5 ข้อ
+	- meetings: แนวทางการประชุม 3-5 ข้อ
+	- roles: บทบาทที่เหมาะกับสมาชิกแต่ละคน อ้างถึงสมาชิกด้วยหมายเลขในรายชื่อ
+	ตอบเป็นภาษาไทย และตอบเป็น JSON ตาม schema เท่านั้น
+`, len(members), roster.String(), formatShares(analysis.Average), analysis.Balance*100,
+		strings.Join(styles, ", "), orNone(frictions), strings.Join(passages, "\n\n"))
+}
+
+func formatShares(scores map[string]float64) string {
+	parts := make([]string, 0, len(scoring.Dimensions))
+	for _, dim := range scoring.Dimensions {
+		parts = append(parts, fmt.Sprintf("%s %.0f%%", dim, scores[dim]))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func orNone(items []string) string {
+	if len(items) == 0 {
+		return "ไม่มี"
+	}
+	return strings.Join(items, "; ")
+}
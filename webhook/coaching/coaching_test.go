@@ -0,0 +1,175 @@
+package coaching
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"line-chatbot-golang-langchain/llm"
+	"line-chatbot-golang-langchain/team"
+)
+
+var members = []team.Member{
+	{UserID: "U1", Style: "D"},
+	{UserID: "U2", Style: "SC"},
+}
+
+const validReport = `{
+	"summary": " ทีมเร็วแต่รอบคอบ ",
+	"communication": ["สรุปประเด็นก่อนลงรายละเอียด", "  "],
+	"meetings": ["ส่งวาระล่วงหน้า"],
+	"roles": [{"member": 2, "role": "ตรวจคุณภาพ", "reason": "ละเอียด"}, {"member": 1, "role": "ตัดสินใจ", "reason": "เด็ดขาด"}]
+}`
+
+func TestParse(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		raw     string
+		roles   []Role
+		wantErr bool
+	}{
+		{
+			name: "plain JSON",
+			raw:  validReport,
+			roles: []Role{
+				{UserID: "U2", Style: "SC", Role: "ตรวจคุณภาพ", Reason: "ละเอียด"},
+				{UserID: "U1", Style: "D", Role: "ตัดสินใจ", Reason: "เด็ดขาด"},
+			},
+		},
+		{
+			name: "prose around the object",
+			raw:  "คำแนะนำสำหรับทีม:\n```json\n" + validReport + "\n```\nหวังว่าจะช่วยได้",
+			roles: []Role{
+				{UserID: "U2", Style: "SC", Role: "ตรวจคุณภาพ", Reason: "ละเอียด"},
+				{UserID: "U1", Style: "D", Role: "ตัดสินใจ", Reason: "เด็ดขาด"},
+			},
+		},
+		{
+			name: "duplicate member keeps the first role",
+			raw: `{"summary": "s", "communication": ["c"], "meetings": ["m"], "roles": [
+				{"member": 1, "role": "ผู้นำ", "reason": "a"},
+				{"member": 1, "role": "ผู้ตาม", "reason": "b"}]}`,
+			roles: []Role{{UserID: "U1", Style: "D", Role: "ผู้นำ", Reason: "a"}},
+		},
+		{
+			name: "blank role is skipped",
+			raw: `{"summary": "s", "communication": ["c"], "meetings": ["m"], "roles": [
+				{"member": 1, "role": " ", "reason": "a"},
+				{"member": 1, "role": "ผู้นำ", "reason": "b"}]}`,
+			roles: []Role{{UserID: "U1", Style: "D", Role: "ผู้นำ", Reason: "b"}},
+		},
+		{
+			name:    "member number zero",
+			raw:     `{"summary": "s", "communication": ["c"], "meetings": ["m"], "roles": [{"member": 0, "role": "r", "reason": "x"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "member number past the roster",
+			raw:     `{"summary": "s", "communication": ["c"], "meetings": ["m"], "roles": [{"member": 3, "role": "r", "reason": "x"}]}`,
+			wantErr: true,
+		},
+		{
+			name:    "empty communication guidelines",
+			raw:     `{"summary": "s", "communication": [" "], "meetings": ["m"], "roles": []}`,
+			wantErr: true,
+		},
+		{
+			name:    "no meeting guidelines",
+			raw:     `{"summary": "s", "communication": ["c"], "roles": []}`,
+			wantErr: true,
+		},
+		{name: "no object", raw: "ทีมนี้ดีมาก", wantErr: true},
+		{name: "wrong field type", raw: `{"summary": "s", "communication": "c", "meetings": ["m"], "roles": []}`, wantErr: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw, members)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidOutput) {
+					t.Errorf("Parse = %+v, %v, want ErrInvalidOutput", got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Roles, tt.roles) {
+				t.Errorf("Roles = %+v, want %+v", got.Roles, tt.roles)
+			}
+		})
+	}
+
+	got, err := Parse(validReport, members)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Summary != "ทีมเร็วแต่รอบคอบ" || !reflect.DeepEqual(got.Communication, []string{"สรุปประเด็นก่อนลงรายละเอียด"}) {
+		t.Errorf("Parse = %q %q, want trimmed text without blank guidelines", got.Summary, got.Communication)
+	}
+}
+
+func TestAdviseRetriesInvalidOutput(t *testing.T) {
+	fake := llm.NewFake(
+		llm.FakeReply{Text: `{"summary": "s", "communication": ["c"], "meetings": ["m"], "roles": [{"member": 7, "role": "r", "reason": "x"}]}`},
+		llm.FakeReply{Text: validReport},
+	)
+	c := &Coach{
+		LLM: fake,
+		Retrieve: func(ctx context.Context, query string) ([]string, error) {
+			return []string{"passage about D and S"}, nil
+		},
+	}
+
+	got, err := c.Advise(context.Background(), team.Analyze(members), members)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.LLMModel != "fake" || len(got.Roles) != 2 {
+		t.Errorf("Advise = %+v, want two roles from fake", got)
+	}
+
+	prompts := fake.Prompts()
+	if len(prompts) != 2 {
+		t.Fatalf("%d prompts, want 2", len(prompts))
+	}
+	if !strings.Contains(prompts[0], "passage about D and S") {
+		t.Error("retrieved passage missing from the prompt")
+	}
+	if !strings.Contains(prompts[1], "คำตอบก่อนหน้าไม่ถูกต้อง") || !strings.Contains(prompts[1], "unknown member 7") {
+		t.Errorf("retry prompt does not explain the error: %q", prompts[1])
+	}
+}
+
+func TestAdviseGivesUp(t *testing.T) {
+	fake := llm.NewFake()
+	fake.Default = &llm.FakeReply{Text: `{"summary": "s", "communication": [], "meetings": [], "roles": []}`}
+	c := &Coach{
+		LLM:         fake,
+		MaxAttempts: 3,
+		Retrieve: func(ctx context.Context, query string) ([]string, error) {
+			return nil, errors.New("search down")
+		},
+	}
+
+	_, err := c.Advise(context.Background(), team.Analyze(members), members)
+	if !errors.Is(err, ErrInvalidOutput) {
+		t.Fatalf("Advise = %v, want ErrInvalidOutput", err)
+	}
+	if n := len(fake.Prompts()); n != 3 {
+		t.Errorf("%d attempts, want 3", n)
+	}
+}
+
+func TestAdviseLLMErrorIsNotRetried(t *testing.T) {
+	unavailable := errors.New("unavailable")
+	fake := llm.NewFake(llm.FakeReply{Err: unavailable})
+	c := &Coach{LLM: fake}
+
+	if _, err := c.Advise(context.Background(), team.Analyze(members), members); !errors.Is(err, unavailable) {
+		t.Fatalf("Advise = %v, want the LLM error", err)
+	}
+	if n := len(fake.Prompts()); n != 1 {
+		t.Errorf("%d attempts, want 1", n)
+	}
+}
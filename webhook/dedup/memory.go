@@ -0,0 +1,71 @@
+package dedup
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type memoryEntry struct {
+	status     string
+	leaseUntil time.Time
+	expireAt   time.Time
+}
+
+// MemoryStore keeps event records in process memory. Records are lost on
+// restart, so it only suits a single instance or local development.
+type MemoryStore struct {
+	opts    Options
+	now     func() time.Time
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+}
+
+func NewMemoryStore(opts Options) *MemoryStore {
+	return &MemoryStore{
+		opts:    opts.withDefaults(),
+		now:     time.Now,
+		entries: map[string]memoryEntry{},
+	}
+}
+
+func (s *MemoryStore) Claim(_ context.Context, eventID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.prune(now)
+
+	if entry, ok := s.entries[eventID]; ok {
+		if entry.status == statusDone || now.Before(entry.leaseUntil) {
+			return false, nil
+		}
+	}
+
+	s.entries[eventID] = memoryEntry{
+		status:     statusProcessing,
+		leaseUntil: now.Add(s.opts.Lease),
+		expireAt:   now.Add(s.opts.TTL),
+	}
+	return true, nil
+}
+
+func (s *MemoryStore) Complete(_ context.Context, eventID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.entries[eventID] = memoryEntry{
+		status:   statusDone,
+		expireAt: now.Add(s.opts.TTL),
+	}
+	return nil
+}
+
+func (s *MemoryStore) prune(now time.Time) {
+	for id, entry := range s.entries {
+		if now.After(entry.expireAt) {
+			delete(s.entries, id)
+		}
+	}
+}
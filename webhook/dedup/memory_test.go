@@ -0,0 +1,115 @@
+package dedup
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// clock is a manual time source for the store's now hook.
+type clock struct{ t time.Time }
+
+func newClock() *clock {
+	return &clock{t: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+}
+
+func (c *clock) now() time.Time          { return c.t }
+func (c *clock) advance(d time.Duration) { c.t = c.t.Add(d) }
+
+func newTestStore(c *clock) *MemoryStore {
+	s := NewMemoryStore(Options{TTL: time.Hour, Lease: time.Minute})
+	s.now = c.now
+	return s
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	type step struct {
+		advance  time.Duration
+		complete bool // call Complete instead of Claim
+		claimed  bool
+	}
+	for _, tt := range []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name:  "first claim wins, duplicate in flight is skipped",
+			steps: []step{{claimed: true}, {claimed: false}},
+		},
+		{
+			name:  "claim held until the lease ends",
+			steps: []step{{claimed: true}, {advance: time.Minute - time.Second, claimed: false}},
+		},
+		{
+			name:  "abandoned claim is handed over after the lease",
+			steps: []step{{claimed: true}, {advance: time.Minute, claimed: true}, {claimed: false}},
+		},
+		{
+			name:  "done is never re-claimed within the TTL",
+			steps: []step{{claimed: true}, {complete: true}, {advance: 30 * time.Minute, claimed: false}},
+		},
+		{
+			name:  "done record pruned after the TTL",
+			steps: []step{{claimed: true}, {complete: true}, {advance: time.Hour + time.Second, claimed: true}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newClock()
+			s := newTestStore(c)
+			for i, st := range tt.steps {
+				c.advance(st.advance)
+				if st.complete {
+					if err := s.Complete(ctx, "ev1"); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				claimed, err := s.Claim(ctx, "ev1")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if claimed != st.claimed {
+					t.Fatalf("step %d: Claim = %t, want %t", i, claimed, st.claimed)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryStorePrunesExpiredEntries(t *testing.T) {
+	ctx := context.Background()
+	c := newClock()
+	s := newTestStore(c)
+
+	for _, id := range []string{"old1", "old2"} {
+		if _, err := s.Claim(ctx, id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Complete(ctx, "old2"); err != nil {
+		t.Fatal(err)
+	}
+
+	c.advance(30 * time.Minute)
+	if _, err := s.Claim(ctx, "fresh"); err != nil {
+		t.Fatal(err)
+	}
+	if len(s.entries) != 3 {
+		t.Fatalf("%d entries before the TTL, want 3", len(s.entries))
+	}
+
+	c.advance(31 * time.Minute)
+	if _, err := s.Claim(ctx, "new"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := s.entries["old1"]; ok {
+		t.Error("expired claim kept")
+	}
+	if _, ok := s.entries["old2"]; ok {
+		t.Error("expired done record kept")
+	}
+	if len(s.entries) != 2 {
+		t.Errorf("%d entries after the TTL, want fresh and new", len(s.entries))
+	}
+}
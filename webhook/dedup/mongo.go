@@ -0,0 +1,75 @@
+package dedup
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MongoStore keeps event records in a collection so every instance behind
+// the webhook URL shares them. A TTL index on expireAt removes old records.
+type MongoStore struct {
+	coll *mongo.Collection
+	opts Options
+}
+
+func NewMongoStore(ctx context.Context, coll *mongo.Collection, opts Options) (*MongoStore, error) {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "expireAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	}
+	if _, err := coll.Indexes().CreateOne(ctx, index); err != nil {
+		return nil, err
+	}
+	return &MongoStore{coll: coll, opts: opts.withDefaults()}, nil
+}
+
+func (s *MongoStore) Claim(ctx context.Context, eventID string) (bool, error) {
+	now := time.Now()
+	_, err := s.coll.InsertOne(ctx, bson.M{
+		"_id":        eventID,
+		"status":     statusProcessing,
+		"attempts":   1,
+		"leaseUntil": now.Add(s.opts.Lease),
+		"expireAt":   now.Add(s.opts.TTL),
+	})
+	if err == nil {
+		return true, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return false, err
+	}
+
+	// The event was seen before: take it over only if the previous claim
+	// was abandoned before completing.
+	filter := bson.M{
+		"_id":        eventID,
+		"status":     statusProcessing,
+		"leaseUntil": bson.M{"$lt": now},
+	}
+	update := bson.M{
+		"$set": bson.M{"leaseUntil": now.Add(s.opts.Lease)},
+		"$inc": bson.M{"attempts": 1},
+	}
+	res, err := s.coll.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount == 1, nil
+}
+
+func (s *MongoStore) Complete(ctx context.Context, eventID string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"status":      statusDone,
+			"completedAt": time.Now(),
+			"expireAt":    time.Now().Add(s.opts.TTL),
+		},
+		"$unset": bson.M{"leaseUntil": ""},
+	}
+	_, err := s.coll.UpdateOne(ctx, bson.M{"_id": eventID}, update, options.UpdateOne().SetUpsert(true))
+	return err
+}
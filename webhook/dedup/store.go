@@ -0,0 +1,39 @@
+package dedup
+
+import (
+	"context"
+	"time"
+)
+
+// Store remembers which webhook events have been processed, keyed on
+// webhookEventId, so redelivered events are not handled twice.
+type Store interface {
+	// Claim marks the event as in progress. It returns false when the event
+	// was already completed or another worker holds a fresh claim on it. A
+	// claim older than the lease is treated as abandoned and handed over,
+	// so a half-finished event is resumed on redelivery.
+	Claim(ctx context.Context, eventID string) (bool, error)
+	// Complete marks a claimed event as done until the TTL expires.
+	Complete(ctx context.Context, eventID string) error
+}
+
+const (
+	statusProcessing = "processing"
+	statusDone       = "done"
+)
+
+// Options control how long records are kept and how long a claim lasts.
+type Options struct {
+	TTL   time.Duration
+	Lease time.Duration
+}
+
+func (o Options) withDefaults() Options {
+	if o.TTL <= 0 {
+		o.TTL = 24 * time.Hour
+	}
+	if o.Lease <= 0 {
+		o.Lease = 5 * time.Minute
+	}
+	return o
+}
@@ -0,0 +1,37 @@
+package embed
+
+import (
+	"context"
+	"fmt"
+)
+
+type batched struct {
+	Embedder
+	size int
+}
+
+// Batched splits large EmbedDocuments calls into requests of at most size
+// texts, so ingesting a whole document never hits provider limits.
+func Batched(e Embedder, size int) Embedder {
+	if size <= 0 {
+		return e
+	}
+	return &batched{Embedder: e, size: size}
+}
+
+func (b *batched) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += b.size {
+		end := min(start+b.size, len(texts))
+		batch, err := b.Embedder.EmbedDocuments(ctx, texts[start:end])
+		if err != nil {
+			return nil, fmt.Errorf("embed texts %d-%d: %w", start, end-1, err)
+		}
+		vectors = append(vectors, batch...)
+	}
+	return vectors, nil
+}
+
+func (b *batched) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return b.Embedder.EmbedQuery(ctx, text)
+}
@@ -0,0 +1,124 @@
+package embed
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+)
+
+// Cache stores vectors by content hash. Implementations must be safe for
+// concurrent use; a missing key is simply absent from the result.
+type Cache interface {
+	GetMany(ctx context.Context, keys []string) (map[string][]float32, error)
+	PutMany(ctx context.Context, vectors map[string][]float32) error
+}
+
+// Key is the cache key of a text embedded by a model at a vector size: the
+// SHA-256 of all three, so the same chunk is embedded once per model and
+// size however often it is seen. Models such as text-embedding-3 can be
+// asked for shorter vectors under the same name.
+func Key(model string, dims int, text string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s", model, dims, text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Cached looks texts up in each cache layer in turn (e.g. an LRU in front of
+// MongoDB) and only sends the misses to the embedder. Cache failures are
+// logged and treated as misses: the cache must never break retrieval.
+type Cached struct {
+	Embedder
+	layers []Cache
+}
+
+func NewCached(e Embedder, layers ...Cache) *Cached {
+	return &Cached{Embedder: e, layers: layers}
+}
+
+func (c *Cached) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	keys := make([]string, len(texts))
+	found := make(map[string][]float32, len(texts))
+	missing := make([]string, 0, len(texts))
+	seen := make(map[string]bool, len(texts))
+	for i, text := range texts {
+		keys[i] = Key(c.Model(), c.Dimensions(), text)
+		if !seen[keys[i]] {
+			seen[keys[i]] = true
+			missing = append(missing, keys[i])
+		}
+	}
+
+	for depth, layer := range c.layers {
+		if len(missing) == 0 {
+			break
+		}
+		hits, err := layer.GetMany(ctx, missing)
+		if err != nil {
+			log.Println("⚠️ Embedding cache lookup failed:", err)
+			continue
+		}
+		if len(hits) == 0 {
+			continue
+		}
+		// Promote hits into the faster layers in front of this one.
+		for _, upper := range c.layers[:depth] {
+			if err := upper.PutMany(ctx, hits); err != nil {
+				log.Println("⚠️ Embedding cache write failed:", err)
+			}
+		}
+		remaining := missing[:0]
+		for _, key := range missing {
+			if v, ok := hits[key]; ok {
+				found[key] = v
+			} else {
+				remaining = append(remaining, key)
+			}
+		}
+		missing = remaining
+	}
+
+	if len(missing) > 0 {
+		want := make(map[string]bool, len(missing))
+		for _, key := range missing {
+			want[key] = true
+		}
+		var toEmbed []string
+		var toEmbedKeys []string
+		for i, text := range texts {
+			if want[keys[i]] {
+				toEmbed = append(toEmbed, text)
+				toEmbedKeys = append(toEmbedKeys, keys[i])
+				delete(want, keys[i])
+			}
+		}
+
+		vectors, err := c.Embedder.EmbedDocuments(ctx, toEmbed)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkVectors(vectors, len(toEmbed), c.Dimensions()); err != nil {
+			return nil, err
+		}
+		fresh := make(map[string][]float32, len(vectors))
+		for i, v := range vectors {
+			fresh[toEmbedKeys[i]] = v
+			found[toEmbedKeys[i]] = v
+		}
+		for _, layer := range c.layers {
+			if err := layer.PutMany(ctx, fresh); err != nil {
+				log.Println("⚠️ Embedding cache write failed:", err)
+			}
+		}
+	}
+
+	out := make([][]float32, len(texts))
+	for i, key := range keys {
+		out[i] = found[key]
+	}
+	return out, nil
+}
+
+func (c *Cached) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return first(c.EmbedDocuments(ctx, []string{text}))
+}
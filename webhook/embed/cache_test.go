@@ -0,0 +1,208 @@
+package embed
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeEmbedder returns a vector per text derived from its length and
+// records every batch it is asked for.
+type fakeEmbedder struct {
+	mu      sync.Mutex
+	model   string
+	dims    int
+	calls   [][]string
+	err     error
+	badDims bool
+}
+
+func (f *fakeEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = append(f.calls, append([]string(nil), texts...))
+	if f.err != nil {
+		return nil, f.err
+	}
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vectors[i] = vectorFor(text, f.dims)
+		if f.badDims {
+			vectors[i] = vectors[i][:1]
+		}
+	}
+	return vectors, nil
+}
+
+func (f *fakeEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return first(f.EmbedDocuments(ctx, []string{text}))
+}
+
+func (f *fakeEmbedder) Model() string          { return f.model }
+func (f *fakeEmbedder) Dimensions() int        { return f.dims }
+func (f *fakeEmbedder) Similarity() Similarity { return Cosine }
+
+func vectorFor(text string, dims int) []float32 {
+	v := make([]float32, dims)
+	v[0] = float32(len(text))
+	return v
+}
+
+// countingCache wraps a cache layer and counts lookups and writes; err
+// makes every call fail.
+type countingCache struct {
+	Cache
+	gets, puts int
+	err        error
+}
+
+func (c *countingCache) GetMany(ctx context.Context, keys []string) (map[string][]float32, error) {
+	c.gets++
+	if c.err != nil {
+		return nil, c.err
+	}
+	return c.Cache.GetMany(ctx, keys)
+}
+
+func (c *countingCache) PutMany(ctx context.Context, vectors map[string][]float32) error {
+	c.puts++
+	if c.err != nil {
+		return c.err
+	}
+	return c.Cache.PutMany(ctx, vectors)
+}
+
+func TestKey(t *testing.T) {
+	base := Key("m", 768, "text")
+	for name, other := range map[string]string{
+		"model":      Key("m2", 768, "text"),
+		"dimensions": Key("m", 256, "text"),
+		"text":       Key("m", 768, "text2"),
+		// The separator keeps the parts from running into each other.
+		"boundary": Key("m7", 68, "text"),
+	} {
+		if other == base {
+			t.Errorf("changing the %s kept the key", name)
+		}
+	}
+	if Key("m", 768, "text") != base {
+		t.Error("key is not stable")
+	}
+}
+
+func TestCachedLayers(t *testing.T) {
+	ctx := context.Background()
+	e := &fakeEmbedder{model: "m", dims: 3}
+	lru := &countingCache{Cache: NewLRU(10)}
+	mongo := &countingCache{Cache: NewLRU(0)}
+	c := NewCached(e, lru, mongo)
+
+	// "b" is only in the slower layer, as after a restart.
+	if err := mongo.Cache.PutMany(ctx, map[string][]float32{Key("m", 3, "bb"): {9, 9, 9}}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.EmbedDocuments(ctx, []string{"a", "bb", "a", "ccc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := [][]float32{{1, 0, 0}, {9, 9, 9}, {1, 0, 0}, {3, 0, 0}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EmbedDocuments = %v, want %v", got, want)
+	}
+	if !reflect.DeepEqual(e.calls, [][]string{{"a", "ccc"}}) {
+		t.Errorf("embedder calls = %q, want one call for the misses, deduplicated", e.calls)
+	}
+
+	// The slower layer's hit was promoted to the LRU, and the fresh
+	// vectors were written to both.
+	if _, err := c.EmbedDocuments(ctx, []string{"bb", "ccc", "a"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(e.calls) != 1 {
+		t.Errorf("embedder called again for cached texts: %q", e.calls)
+	}
+	if mongo.gets != 1 {
+		t.Errorf("slower layer read %d times, want only on the first miss", mongo.gets)
+	}
+	if n := mongo.Cache.(*LRU).Len(); n != 3 {
+		t.Errorf("slower layer holds %d vectors, want 3", n)
+	}
+
+	// A different vector size must not reuse the vectors above.
+	short := NewCached(&fakeEmbedder{model: "m", dims: 2}, lru, mongo)
+	got, err = short.EmbedDocuments(ctx, []string{"a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got[0]) != 2 {
+		t.Errorf("got a %d-dimension vector from the cache, want 2", len(got[0]))
+	}
+}
+
+func TestCachedSurvivesCacheFailure(t *testing.T) {
+	e := &fakeEmbedder{model: "m", dims: 2}
+	broken := &countingCache{Cache: NewLRU(10), err: errors.New("mongo down")}
+	c := NewCached(e, broken)
+
+	got, err := c.EmbedQuery(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, []float32{3, 0}) {
+		t.Errorf("EmbedQuery = %v", got)
+	}
+	if broken.gets != 1 || broken.puts != 1 {
+		t.Errorf("cache gets %d puts %d, want 1 and 1", broken.gets, broken.puts)
+	}
+}
+
+func TestCachedRejectsBadVectors(t *testing.T) {
+	lru := NewLRU(10)
+	c := NewCached(&fakeEmbedder{model: "m", dims: 3, badDims: true}, lru)
+
+	_, err := c.EmbedDocuments(context.Background(), []string{"a"})
+	if err == nil || !strings.Contains(err.Error(), "dimensions") {
+		t.Fatalf("EmbedDocuments = %v, want a dimensions error", err)
+	}
+	if lru.Len() != 0 {
+		t.Error("vector of the wrong size was cached")
+	}
+}
+
+func TestBatched(t *testing.T) {
+	e := &fakeEmbedder{model: "m", dims: 1}
+	b := Batched(e, 2)
+
+	got, err := b.EmbedDocuments(context.Background(), []string{"a", "bb", "ccc", "dddd", "eeeee"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][]float32{{1}, {2}, {3}, {4}, {5}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("EmbedDocuments = %v, want %v", got, want)
+	}
+	if want := [][]string{{"a", "bb"}, {"ccc", "dddd"}, {"eeeee"}}; !reflect.DeepEqual(e.calls, want) {
+		t.Errorf("batches = %q, want %q", e.calls, want)
+	}
+	if b.Dimensions() != 1 || b.Model() != "m" {
+		t.Error("Batched hides the embedder's model or dimensions")
+	}
+
+	if Batched(e, 0) != Embedder(e) {
+		t.Error("Batched with size 0 should return the embedder unchanged")
+	}
+}
+
+func TestBatchedNamesFailedBatch(t *testing.T) {
+	e := &fakeEmbedder{model: "m", dims: 1, err: errors.New("rate limited")}
+	_, err := Batched(e, 2).EmbedDocuments(context.Background(), []string{"a", "b", "c"})
+	if err == nil || !strings.Contains(err.Error(), "texts 0-1") {
+		t.Errorf("EmbedDocuments = %v, want the failed range", err)
+	}
+	if len(e.calls) != 1 {
+		t.Errorf("%d calls, want to stop after the first failure", len(e.calls))
+	}
+}
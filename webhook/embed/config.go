@@ -0,0 +1,84 @@
+package embed
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// FromEnv builds the configured provider:
+//
+//	EMBEDDING_PROVIDER    huggingface (default), openai or local
+//	EMBEDDING_MODEL       model name; defaults per provider
+//	EMBEDDING_BASE_URL    API root for openai/local
+//	EMBEDDING_API_KEY     key for openai/local
+//	EMBEDDING_DIMENSIONS  vector size; probed from the model when unset
+//	EMBEDDING_SIMILARITY  cosine (default), dotProduct or euclidean
+//
+// Hugging Face reads its token from HUGGINGFACEHUB_API_TOKEN.
+func FromEnv(ctx context.Context) (Embedder, error) {
+	provider := os.Getenv("EMBEDDING_PROVIDER")
+	model := os.Getenv("EMBEDDING_MODEL")
+	dims := 0
+	if value := os.Getenv("EMBEDDING_DIMENSIONS"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid EMBEDDING_DIMENSIONS %q", value)
+		}
+		dims = n
+	}
+
+	sim, err := ParseSimilarity(os.Getenv("EMBEDDING_SIMILARITY"))
+	if err != nil {
+		return nil, err
+	}
+
+	switch provider {
+	case "", "huggingface":
+		if model == "" {
+			model = "sentence-transformers/all-mpnet-base-v2"
+			if dims == 0 {
+				dims = 768
+			}
+		}
+		if dims == 0 {
+			return nil, fmt.Errorf("EMBEDDING_DIMENSIONS is required for Hugging Face model %q", model)
+		}
+		return NewHuggingFace(model, dims, sim)
+	case "openai":
+		baseURL := envOr("EMBEDDING_BASE_URL", "https://api.openai.com/v1")
+		if model == "" {
+			model = "text-embedding-3-small"
+		}
+		return probe(ctx, NewOpenAICompatible(baseURL, os.Getenv("EMBEDDING_API_KEY"), model, dims, sim))
+	case "local":
+		baseURL := envOr("EMBEDDING_BASE_URL", "http://localhost:11434/v1")
+		if model == "" {
+			return nil, fmt.Errorf("EMBEDDING_MODEL is required for the local provider")
+		}
+		return probe(ctx, NewOpenAICompatible(baseURL, os.Getenv("EMBEDDING_API_KEY"), model, dims, sim))
+	default:
+		return nil, fmt.Errorf("unknown EMBEDDING_PROVIDER %q", provider)
+	}
+}
+
+// probe learns the vector size from the server when it was not configured.
+func probe(ctx context.Context, o *OpenAICompatible) (Embedder, error) {
+	if o.dims > 0 {
+		return o, nil
+	}
+	v, err := o.EmbedQuery(ctx, "dimension probe")
+	if err != nil {
+		return nil, fmt.Errorf("probe embedding dimensions: %w", err)
+	}
+	o.dims = len(v)
+	return o, nil
+}
+
+func envOr(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
@@ -0,0 +1,64 @@
+package embed
+
+import (
+	"context"
+	"fmt"
+)
+
+// Embedder turns text into vectors. It is a superset of langchaingo's
+// embeddings.Embedder, so it plugs straight into mongovector.
+type Embedder interface {
+	// EmbedDocuments returns one vector per text, in order.
+	EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error)
+	EmbedQuery(ctx context.Context, text string) ([]float32, error)
+	// Model names the embedding model. Vectors of different models must
+	// never be mixed, so it is part of every cache key, as are Dimensions.
+	Model() string
+	// Dimensions is the length of every vector the model returns.
+	Dimensions() int
+	// Similarity is the function the model's vectors are meant to be
+	// compared with; vector indexes are built for it.
+	Similarity() Similarity
+}
+
+// Similarity is a vector similarity function, named as in Atlas Vector
+// Search.
+type Similarity string
+
+const (
+	Cosine     Similarity = "cosine"
+	DotProduct Similarity = "dotProduct"
+	Euclidean  Similarity = "euclidean"
+)
+
+func ParseSimilarity(value string) (Similarity, error) {
+	switch Similarity(value) {
+	case "", Cosine:
+		return Cosine, nil
+	case DotProduct, Euclidean:
+		return Similarity(value), nil
+	default:
+		return "", fmt.Errorf("unknown similarity %q", value)
+	}
+}
+
+// checkVectors makes sure a provider answered with one vector of the
+// expected size per text.
+func checkVectors(vectors [][]float32, texts, dims int) error {
+	if len(vectors) != texts {
+		return fmt.Errorf("embedder returned %d vectors for %d texts", len(vectors), texts)
+	}
+	for i, v := range vectors {
+		if dims > 0 && len(v) != dims {
+			return fmt.Errorf("vector %d has %d dimensions, want %d", i, len(v), dims)
+		}
+	}
+	return nil
+}
+
+func first(vectors [][]float32, err error) ([]float32, error) {
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
@@ -0,0 +1,55 @@
+package embed
+
+import (
+	"context"
+
+	"github.com/tmc/langchaingo/embeddings/huggingface"
+)
+
+// HuggingFace calls the Hugging Face inference API through langchaingo.
+// The token is read from HUGGINGFACEHUB_API_TOKEN.
+type HuggingFace struct {
+	client *huggingface.Huggingface
+	model  string
+	dims   int
+	sim    Similarity
+}
+
+func NewHuggingFace(model string, dims int, sim Similarity) (*HuggingFace, error) {
+	client, err := huggingface.NewHuggingface(
+		huggingface.WithModel(model),
+		huggingface.WithTask("feature-extraction"))
+	if err != nil {
+		return nil, err
+	}
+	return &HuggingFace{client: client, model: model, dims: dims, sim: sim}, nil
+}
+
+func (h *HuggingFace) Model() string {
+	return h.model
+}
+
+func (h *HuggingFace) Dimensions() int {
+	return h.dims
+}
+
+func (h *HuggingFace) Similarity() Similarity {
+	return h.sim
+}
+
+func (h *HuggingFace) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	// langchaingo strips newlines in place; keep the caller's slice intact.
+	input := append([]string(nil), texts...)
+	vectors, err := h.client.EmbedDocuments(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVectors(vectors, len(texts), h.dims); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+func (h *HuggingFace) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return first(h.EmbedDocuments(ctx, []string{text}))
+}
@@ -0,0 +1,68 @@
+package embed
+
+import (
+	"container/list"
+	"context"
+	"sync"
+)
+
+// LRU is an in-memory Cache holding the most recently used vectors.
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruEntry struct {
+	key    string
+	vector []float32
+}
+
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (l *LRU) GetMany(_ context.Context, keys []string) (map[string][]float32, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hits := make(map[string][]float32)
+	for _, key := range keys {
+		if el, ok := l.items[key]; ok {
+			l.order.MoveToFront(el)
+			hits[key] = el.Value.(*lruEntry).vector
+		}
+	}
+	return hits, nil
+}
+
+func (l *LRU) PutMany(_ context.Context, vectors map[string][]float32) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, vector := range vectors {
+		if el, ok := l.items[key]; ok {
+			el.Value.(*lruEntry).vector = vector
+			l.order.MoveToFront(el)
+			continue
+		}
+		l.items[key] = l.order.PushFront(&lruEntry{key: key, vector: vector})
+		for l.capacity > 0 && l.order.Len() > l.capacity {
+			oldest := l.order.Back()
+			l.order.Remove(oldest)
+			delete(l.items, oldest.Value.(*lruEntry).key)
+		}
+	}
+	return nil
+}
+
+func (l *LRU) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
@@ -0,0 +1,58 @@
+package embed
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// MongoCache keeps vectors in a collection keyed by content hash, so they
+// survive restarts and are shared by every instance of the bot.
+type MongoCache struct {
+	coll *mongo.Collection
+}
+
+type cachedVector struct {
+	Key       string    `bson:"_id"`
+	Vector    []float32 `bson:"vector"`
+	CreatedAt time.Time `bson:"createdAt"`
+}
+
+func NewMongoCache(coll *mongo.Collection) *MongoCache {
+	return &MongoCache{coll: coll}
+}
+
+func (m *MongoCache) GetMany(ctx context.Context, keys []string) (map[string][]float32, error) {
+	cursor, err := m.coll.Find(ctx, bson.M{"_id": bson.M{"$in": keys}})
+	if err != nil {
+		return nil, err
+	}
+	var docs []cachedVector
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	hits := make(map[string][]float32, len(docs))
+	for _, doc := range docs {
+		hits[doc.Key] = doc.Vector
+	}
+	return hits, nil
+}
+
+func (m *MongoCache) PutMany(ctx context.Context, vectors map[string][]float32) error {
+	if len(vectors) == 0 {
+		return nil
+	}
+	now := time.Now()
+	writes := make([]mongo.WriteModel, 0, len(vectors))
+	for key, vector := range vectors {
+		writes = append(writes, mongo.NewReplaceOneModel().
+			SetFilter(bson.M{"_id": key}).
+			SetReplacement(cachedVector{Key: key, Vector: vector, CreatedAt: now}).
+			SetUpsert(true))
+	}
+	_, err := m.coll.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
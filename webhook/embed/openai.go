@@ -0,0 +1,110 @@
+package embed
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// OpenAICompatible calls a server implementing the OpenAI embeddings API.
+// Ollama, llama.cpp, LocalAI and text-embeddings-inference all serve it, so
+// the whole pipeline can run offline against a local model.
+type OpenAICompatible struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	dims       int
+	sim        Similarity
+	httpClient *http.Client
+}
+
+// NewOpenAICompatible takes the API root, e.g. "http://localhost:11434/v1".
+// apiKey may be empty for local servers.
+func NewOpenAICompatible(baseURL, apiKey, model string, dims int, sim Similarity) *OpenAICompatible {
+	return &OpenAICompatible{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		dims:       dims,
+		sim:        sim,
+		httpClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+func (o *OpenAICompatible) Model() string {
+	return o.model
+}
+
+func (o *OpenAICompatible) Dimensions() int {
+	return o.dims
+}
+
+func (o *OpenAICompatible) Similarity() Similarity {
+	return o.sim
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (o *OpenAICompatible) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	body, err := json.Marshal(embeddingRequest{Model: o.model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/embeddings", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, fmt.Errorf("embeddings API %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var parsed embeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("decode embeddings: %w", err)
+	}
+	sort.Slice(parsed.Data, func(i, j int) bool { return parsed.Data[i].Index < parsed.Data[j].Index })
+
+	vectors := make([][]float32, 0, len(parsed.Data))
+	for _, d := range parsed.Data {
+		vectors = append(vectors, d.Embedding)
+	}
+	if err := checkVectors(vectors, len(texts), o.dims); err != nil {
+		return nil, err
+	}
+	return vectors, nil
+}
+
+func (o *OpenAICompatible) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return first(o.EmbedDocuments(ctx, []string{text}))
+}
@@ -1,134 +1,141 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/questionnaire"
+	"line-chatbot-golang-langchain/service"
 	"line-chatbot-golang-langchain/utils"
 	"log"
 	"net/http"
-	"strings"
+	"sync/atomic"
 )
 
-// InitDiscVectorsHandler เรียกสร้างเวกเตอร์ DISC และ Index
-func InitDiscVectorsHandler(w http.ResponseWriter, r *http.Request) {
-	log.Println("🔧 InitDiscVectorsHandler called - starting async vector initialization...")
-	go utils.InsertVectors() // async ไม่ block user
-	w.WriteHeader(http.StatusOK)
-	_, _ = fmt.Fprintln(w, "✅ กำลังสร้าง DISC embeddings และ index แล้ว...")
-}
-
-func AnswerSubmissionHandler(w http.ResponseWriter, r *http.Request) {
-	log.Println("📥 Received request to /submit-answer")
-
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Groupid")
-	w.Header().Set("Access-Control-Max-Age", "86400") // cache preflight 24h
+var ingesting atomic.Bool
 
-	if r.Method == http.MethodOptions {
-		log.Println("🔁 OPTIONS request received (CORS Preflight)")
+// NewInitDiscVectorsHandler เรียกสร้างเวกเตอร์ DISC และ Index
+// The discctl CLI does the same with progress output; this endpoint only
+// starts one run at a time in the background.
+func NewInitDiscVectorsHandler(kb *utils.Knowledge) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !ingesting.CompareAndSwap(false, true) {
+			http.Error(w, "Ingestion already running", http.StatusConflict)
+			return
+		}
+		log.Println("🔧 InitDiscVectorsHandler called - starting async vector initialization...")
+		// The run outlives the request, but keeps its values.
+		ctx := context.WithoutCancel(r.Context())
+		go func() { // async ไม่ block user
+			defer ingesting.Store(false)
+			defer func() {
+				if p := recover(); p != nil {
+					log.Println("❌ Vector initialization panicked:", p)
+				}
+			}()
+			kb.InsertVectors(ctx)
+		}()
 		w.WriteHeader(http.StatusOK)
-		return
-	}
-
-	if r.Method != http.MethodPost {
-		log.Println("🚫 Invalid HTTP Method:", r.Method)
-		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
-		return
-	}
-
-	groupID := r.Header.Get("groupid")
-	idToken := r.Header.Get("Authorization")
-
-	if idToken == "" {
-		log.Println("🚫 Missing Authorization header")
-		http.Error(w, "Missing groupId or Authorization header", http.StatusBadRequest)
-		return
-	}
-
-	log.Println("🛂 Extracted Headers - groupID:", groupID, "idToken:", idToken)
-
-	var req models.AnswerRequest
-	err := json.NewDecoder(r.Body).Decode(&req)
-	if err != nil {
-		log.Println("🚫 Failed to decode request body:", err)
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		return
-	}
-
-	if len(req.Answers) == 0 {
-		log.Println("🚫 No answers submitted")
-		http.Error(w, "Missing answers", http.StatusBadRequest)
-		return
+		_, _ = fmt.Fprintln(w, "✅ กำลังสร้าง DISC embeddings และ index แล้ว...")
 	}
+}
 
-	var indexedAnswers []string
-	for i, answer := range req.Answers {
-		if len(answer) == 0 {
-			log.Printf("⚠️ Skipping empty answer at index %d\n", i)
-			continue
+// NewAnswerSubmissionHandler serves /submit-answer. Each request gets its
+// own result from the service, so concurrent submissions never mix.
+func NewAnswerSubmissionHandler(svc *service.SubmissionService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		log.Println("📥 Received request to /submit-answer")
+
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Groupid, Roomid")
+		w.Header().Set("Access-Control-Max-Age", "86400") // cache preflight 24h
+
+		if r.Method == http.MethodOptions {
+			log.Println("🔁 OPTIONS request received (CORS Preflight)")
+			w.WriteHeader(http.StatusOK)
+			return
 		}
-		indexed := fmt.Sprintf("%d.%s", i+1, answer)
-		indexedAnswers = append(indexedAnswers, indexed)
-	}
-	formattedAnswers := strings.Join(indexedAnswers, ", ")
 
-	log.Println("✅ Formatted answers:", formattedAnswers)
-
-	profile, err := utils.GetProfileByIDToken(idToken)
-	if err != nil || profile["sub"] == nil {
-		log.Println("🚫 Invalid LINE ID token or missing profile")
-		http.Error(w, "Invalid LINE ID Token", http.StatusUnauthorized)
-		return
-	}
-
-	userID := profile["sub"].(string)
-	log.Println("👤 LINE User ID:", userID)
+		if r.Method != http.MethodPost {
+			log.Println("🚫 Invalid HTTP Method:", r.Method)
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
 
-	prompt := formattedAnswers
-	log.Println("📤 Sending prompt to Gemini:", prompt)
+		sub := service.Submission{
+			GroupID: r.Header.Get("groupid"),
+			RoomID:  r.Header.Get("roomid"),
+			IDToken: r.Header.Get("Authorization"),
+		}
 
-	jsonString, err := utils.VectorSearchQueryGemini(prompt, true)
-	if err != nil {
-		log.Println("❌ Gemini vector search failed:", err)
-		http.Error(w, "Gemini search failed: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
+		if sub.IDToken == "" {
+			log.Println("🚫 Missing Authorization header")
+			http.Error(w, "Missing groupId or Authorization header", http.StatusBadRequest)
+			return
+		}
 
-	log.Println("📥 Gemini JSON Response:", jsonString)
+		log.Println("🛂 Extracted Headers - groupID:", sub.GroupID, "roomID:", sub.RoomID)
 
-	if err := json.Unmarshal([]byte(jsonString), &models.AiResult); err != nil {
-		log.Println("❌ Failed to parse AI response:", err)
-		http.Error(w, "Failed to parse AI response", http.StatusInternalServerError)
-		return
-	}
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&sub.Request); err != nil {
+			log.Println("🚫 Failed to decode request body:", err)
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
 
-	userAnswer := map[string]interface{}{
-		"userId":      userID,
-		"groupId":     groupID,
-		"model":       models.AiResult.Model,
-		"description": models.AiResult.Description,
-		"answers":     req.Answers,
-	}
+		result, err := svc.Submit(r.Context(), sub)
+		if err != nil {
+			var verr *questionnaire.ValidationError
+			switch {
+			case errors.As(err, &verr):
+				log.Println("🚫 Invalid answers at indexes:", verr.InvalidIndexes())
+				writeValidationError(w, verr)
+			case errors.Is(err, service.ErrInvalidSubmission):
+				log.Println("🚫 Invalid submission:", err)
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			case errors.Is(err, service.ErrUnauthorized):
+				log.Println("🚫 Invalid LINE ID token or missing profile")
+				http.Error(w, "Invalid LINE ID Token", http.StatusUnauthorized)
+			default:
+				log.Println("❌ Failed to save user answer:", err)
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+			return
+		}
 
-	log.Println("📝 Saving user answer to MongoDB:", userAnswer)
+		log.Println("✅ User answer saved successfully:", result.UserID)
 
-	if err := utils.UpsertAnswersByUserID(userID, groupID, userAnswer); err != nil {
-		log.Println("❌ Failed to save user answer:", err)
-		http.Error(w, "Mongo save failed: "+err.Error(), http.StatusInternalServerError)
-		return
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		// The LIFF app can show the same card the bot replies with.
+		data := result.Document()
+		data["card"] = discResultCard(result.Score.Style, result.Description, utils.LiffURL(result.Source))
+		err = json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": "User answer saved successfully",
+			"data":    data,
+		})
+		if err != nil {
+			log.Println("⚠️ Failed to encode response:", err)
+		}
 	}
+}
 
-	log.Println("✅ User answer saved successfully")
-
+// writeValidationError answers 422 with every offending answer, e.g.
+// {"error":"invalid_answers","invalidIndexes":[2,4],"issues":[...]}.
+func writeValidationError(w http.ResponseWriter, verr *questionnaire.ValidationError) {
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	err = json.NewEncoder(w).Encode(map[string]interface{}{
-		"message": "User answer saved successfully",
-		"data":    userAnswer,
+	w.WriteHeader(http.StatusUnprocessableEntity)
+	err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"error":          "invalid_answers",
+		"message":        verr.Error(),
+		"version":        verr.Version,
+		"expected":       verr.Expected,
+		"received":       verr.Received,
+		"invalidIndexes": verr.InvalidIndexes(),
+		"issues":         verr.Issues,
 	})
 	if err != nil {
-		log.Println("⚠️ Failed to encode response:", err)
+		log.Println("⚠️ Failed to encode validation error:", err)
 	}
 }
@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"line-chatbot-golang-langchain/linebot"
+	"line-chatbot-golang-langchain/models"
+)
+
+const maxCardDescriptionRunes = 400
+
+// maxCarouselBubbles is the most bubbles LINE shows in one carousel.
+const maxCarouselBubbles = 12
+
+// discResultCard renders a DISC result as a Flex bubble: the type letters on
+// the colour of the primary style, the description, strengths, blind spots,
+// and buttons to share the result or retake the test. A result without a
+// valid type gets a card asking the user to retake the test instead.
+func discResultCard(model, description, liffURL string) linebot.FlexMessage {
+	letters := models.DiscLetters(model)
+	altText := "ผล DISC ของคุณ: " + strings.Join(letters, "")
+	if len(letters) == 0 {
+		altText = "ยังไม่มีผล DISC ที่ถูกต้อง กรุณาทำแบบทดสอบใหม่"
+	}
+	return linebot.FlexMessage{
+		AltText:  truncateRunes(altText, 400),
+		Contents: discResultBubble("", model, description, liffURL),
+	}
+}
+
+// teamResultCarousel shows the cards of a chat's members side by side,
+// each titled with the member's name. Only the first maxCarouselBubbles
+// members fit.
+func teamResultCarousel(results []memberResult, liffURL string) linebot.FlexMessage {
+	carousel := &linebot.Carousel{}
+	for _, r := range results[:min(len(results), maxCarouselBubbles)] {
+		carousel.Contents = append(carousel.Contents, discResultBubble(r.Name, r.Model, r.Description, liffURL))
+	}
+	return linebot.FlexMessage{AltText: "ผล DISC ของสมาชิกในกลุ่ม", Contents: carousel}
+}
+
+// memberResult is a member's result as shown in the team carousel.
+type memberResult struct {
+	Name        string
+	Model       string
+	Description string
+}
+
+// discResultBubble is the card itself. A card with a name belongs to
+// someone else, so it has no share button.
+func discResultBubble(name, model, description, liffURL string) *linebot.Bubble {
+	letters := models.DiscLetters(model)
+	if len(letters) == 0 {
+		return invalidResultBubble(name, liffURL)
+	}
+	primary := models.DiscStyles[letters[0]]
+
+	names := make([]string, 0, len(letters))
+	for _, letter := range letters {
+		names = append(names, models.DiscStyles[letter].Name)
+	}
+
+	header := linebot.NewBox("vertical")
+	if name != "" {
+		header.Contents = append(header.Contents, &linebot.Text{Text: name, Size: "sm", Color: "#FFFFFFCC", Wrap: true})
+	}
+	header.Contents = append(header.Contents,
+		&linebot.Text{Text: strings.Join(letters, ""), Size: "5xl", Weight: "bold", Color: "#FFFFFF"},
+		&linebot.Text{Text: strings.Join(names, " · "), Size: "md", Weight: "bold", Color: "#FFFFFF", Wrap: true},
+		&linebot.Text{Text: primary.ThaiName, Size: "sm", Color: "#FFFFFFCC", Wrap: true},
+	)
+	header.BackgroundColor = primary.Color
+	header.PaddingAll = "20px"
+
+	body := linebot.NewBox("vertical")
+	body.Spacing = "md"
+	if description != "" {
+		body.Contents = append(body.Contents, &linebot.Text{Text: truncateRunes(description, maxCardDescriptionRunes), Size: "sm", Wrap: true, Color: "#555555"})
+		body.Contents = append(body.Contents, &linebot.Separator{Margin: "lg"})
+	}
+	body.Contents = append(body.Contents, cardSection("💪 จุดแข็ง", collect(letters, func(s models.DiscStyle) []string { return s.Strengths }), primary.Color))
+	body.Contents = append(body.Contents, cardSection("⚠️ จุดที่ควรระวัง", collect(letters, func(s models.DiscStyle) []string { return s.BlindSpots }), "#757575"))
+
+	footer := linebot.NewBox("vertical")
+	if name == "" {
+		shareText := fmt.Sprintf("ฉันได้ผล DISC แบบ %s (%s) มาลองทำแบบทดสอบกัน: %s", strings.Join(letters, ""), strings.Join(names, " · "), liffURL)
+		footer.Contents = append(footer.Contents, &linebot.Button{
+			Action: linebot.URIAction{Label: "แชร์ให้กลุ่ม", URI: "https://line.me/R/share?text=" + url.QueryEscape(shareText)},
+			Style:  "primary",
+			Color:  primary.Color,
+		})
+	}
+	footer.Contents = append(footer.Contents, &linebot.Button{
+		Action: linebot.URIAction{Label: "ทำแบบทดสอบใหม่", URI: liffURL},
+		Style:  "secondary",
+	})
+	footer.Spacing = "sm"
+
+	return &linebot.Bubble{Header: header, Body: body, Footer: footer}
+}
+
+// invalidResultBubble stands in for a result whose type is empty or not a
+// DISC type, rather than guessing one.
+func invalidResultBubble(name, liffURL string) *linebot.Bubble {
+	header := linebot.NewBox("vertical")
+	if name != "" {
+		header.Contents = append(header.Contents, &linebot.Text{Text: name, Size: "sm", Color: "#FFFFFFCC", Wrap: true})
+	}
+	header.Contents = append(header.Contents,
+		&linebot.Text{Text: "?", Size: "5xl", Weight: "bold", Color: "#FFFFFF"},
+		&linebot.Text{Text: "ยังไม่มีผลที่ถูกต้อง", Size: "md", Weight: "bold", Color: "#FFFFFF", Wrap: true},
+	)
+	header.BackgroundColor = "#9E9E9E"
+	header.PaddingAll = "20px"
+
+	body := linebot.NewBox("vertical",
+		&linebot.Text{Text: "ผลแบบทดสอบที่บันทึกไว้อ่านไม่ได้ กรุณาทำแบบทดสอบใหม่อีกครั้งนะครับ 🙏", Size: "sm", Wrap: true, Color: "#555555"},
+	)
+
+	footer := linebot.NewBox("vertical", &linebot.Button{
+		Action: linebot.URIAction{Label: "ทำแบบทดสอบใหม่", URI: liffURL},
+		Style:  "primary",
+		Color:  "#757575",
+	})
+
+	return &linebot.Bubble{Header: header, Body: body, Footer: footer}
+}
+
+// resultLabel is the type letters of a stored result for text replies, or
+// a note that it is not valid.
+func resultLabel(model string) string {
+	letters := models.DiscLetters(model)
+	if len(letters) == 0 {
+		return "ยังไม่มีผลที่ถูกต้อง (กรุณาทำแบบทดสอบใหม่)"
+	}
+	return "อยู่ในกลุ่ม " + strings.Join(letters, "")
+}
+
+func cardSection(title string, items []string, color string) *linebot.Box {
+	section := linebot.NewBox("vertical", &linebot.Text{Text: title, Weight: "bold", Size: "sm", Color: color})
+	section.Spacing = "xs"
+	for _, item := range items {
+		section.Contents = append(section.Contents, &linebot.Text{Text: "• " + item, Size: "sm", Wrap: true})
+	}
+	return section
+}
+
+// collect merges a list from every style of a blended type, e.g. the
+// strengths of both D and I for "DI".
+func collect(letters []string, pick func(models.DiscStyle) []string) []string {
+	var items []string
+	for _, letter := range letters {
+		items = append(items, pick(models.DiscStyles[letter])...)
+	}
+	return items
+}
+
+func truncateRunes(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
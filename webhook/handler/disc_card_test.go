@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testLiffURL = "https://liff.line.me/1234-abcd?groupid=C1"
+
+// decode marshals a card the way it is sent to LINE and reads it back as
+// plain JSON.
+func decode(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+// checkTypes walks a decoded message and reports every container,
+// component or action without a "type", and every null value.
+func checkTypes(t *testing.T, path string, node map[string]interface{}) {
+	t.Helper()
+	if _, ok := node["type"].(string); !ok {
+		t.Errorf("%s has no type: %v", path, node)
+	}
+	for key, value := range node {
+		switch value := value.(type) {
+		case nil:
+			t.Errorf("%s.%s is null", path, key)
+		case map[string]interface{}:
+			if key != "styles" {
+				checkTypes(t, path+"."+key, value)
+			}
+		case []interface{}:
+			for _, item := range value {
+				if child, ok := item.(map[string]interface{}); ok {
+					checkTypes(t, path+"."+key+"[]", child)
+				}
+			}
+		}
+	}
+}
+
+// uris collects the URI actions of a bubble's footer by label.
+func uris(t *testing.T, bubble map[string]interface{}) map[string]string {
+	t.Helper()
+	out := map[string]string{}
+	footer, _ := bubble["footer"].(map[string]interface{})
+	buttons, _ := footer["contents"].([]interface{})
+	for _, b := range buttons {
+		action := b.(map[string]interface{})["action"].(map[string]interface{})
+		if action["type"] == "uri" {
+			out[action["label"].(string)] = action["uri"].(string)
+		}
+	}
+	return out
+}
+
+func TestDiscResultCard(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		model   string
+		altText string
+		share   bool
+	}{
+		{name: "single style", model: "D", altText: "ผล DISC ของคุณ: D", share: true},
+		{name: "blend", model: "SC", altText: "ผล DISC ของคุณ: SC", share: true},
+		{name: "legacy label", model: "DI (Dominance/Influence)", altText: "ผล DISC ของคุณ: DI", share: true},
+		{name: "invalid", model: "X", altText: "ยังไม่มีผล DISC ที่ถูกต้อง กรุณาทำแบบทดสอบใหม่"},
+		{name: "empty", model: "", altText: "ยังไม่มีผล DISC ที่ถูกต้อง กรุณาทำแบบทดสอบใหม่"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			message := decode(t, discResultCard(tt.model, "คำอธิบาย", testLiffURL))
+			checkTypes(t, "message", message)
+			if message["type"] != "flex" || message["altText"] != tt.altText {
+				t.Errorf("type %v altText %v, want flex %q", message["type"], message["altText"], tt.altText)
+			}
+
+			bubble := message["contents"].(map[string]interface{})
+			if bubble["type"] != "bubble" {
+				t.Fatalf("contents type = %v, want bubble", bubble["type"])
+			}
+
+			got := uris(t, bubble)
+			if got["ทำแบบทดสอบใหม่"] != testLiffURL {
+				t.Errorf("retake URI = %q, want %q", got["ทำแบบทดสอบใหม่"], testLiffURL)
+			}
+			share, ok := got["แชร์ให้กลุ่ม"]
+			if ok != tt.share {
+				t.Fatalf("share button = %t, want %t", ok, tt.share)
+			}
+			if !tt.share {
+				return
+			}
+			u, err := url.Parse(share)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if u.Scheme != "https" || u.Host != "line.me" || u.Path != "/R/share" {
+				t.Errorf("share URI = %q, want https://line.me/R/share", share)
+			}
+			if text := u.Query().Get("text"); !strings.HasSuffix(text, testLiffURL) {
+				t.Errorf("share text %q does not end with the LIFF URL", text)
+			}
+		})
+	}
+}
+
+func TestTeamResultCarousel(t *testing.T) {
+	results := make([]memberResult, maxCarouselBubbles+2)
+	for i := range results {
+		results[i] = memberResult{Name: "สมาชิก", Model: "I"}
+	}
+	results[1].Model = "broken"
+
+	message := decode(t, teamResultCarousel(results, testLiffURL))
+	checkTypes(t, "message", message)
+
+	carousel := message["contents"].(map[string]interface{})
+	if carousel["type"] != "carousel" {
+		t.Fatalf("contents type = %v, want carousel", carousel["type"])
+	}
+	bubbles := carousel["contents"].([]interface{})
+	if len(bubbles) != maxCarouselBubbles {
+		t.Fatalf("%d bubbles, want %d", len(bubbles), maxCarouselBubbles)
+	}
+	for i, b := range bubbles {
+		got := uris(t, b.(map[string]interface{}))
+		if _, ok := got["แชร์ให้กลุ่ม"]; ok {
+			t.Errorf("bubble %d of another member has a share button", i)
+		}
+		if got["ทำแบบทดสอบใหม่"] != testLiffURL {
+			t.Errorf("bubble %d retake URI = %q", i, got["ทำแบบทดสอบใหม่"])
+		}
+	}
+
+	invalid := decode(t, invalidResultBubble("สมาชิก", testLiffURL))
+	header := invalid["header"].(map[string]interface{})
+	if header["backgroundColor"] != "#9E9E9E" {
+		t.Errorf("invalid header colour = %v", header["backgroundColor"])
+	}
+	if broken := bubbles[1].(map[string]interface{}); broken["header"].(map[string]interface{})["backgroundColor"] != "#9E9E9E" {
+		t.Error("member with an invalid result did not get the invalid card")
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"line-chatbot-golang-langchain/dedup"
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/utils"
+	"line-chatbot-golang-langchain/worker"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// EventQueue processes webhook events after the webhook has been
+// acknowledged, skipping events the dedup store has already seen.
+type EventQueue struct {
+	pool  *worker.Pool
+	store dedup.Store
+	// timeout bounds how long one event may run. It stays below the dedup
+	// lease so a slow event is cancelled before another worker may
+	// re-claim it.
+	timeout time.Duration
+}
+
+func eventTimeoutFor(lease time.Duration) time.Duration {
+	return lease - lease/5
+}
+
+// NewEventQueue starts the worker pool and sets up webhookEventId
+// deduplication:
+//
+//	WEBHOOK_WORKERS      workers (default 4)
+//	WEBHOOK_QUEUE_SIZE   queued events before the webhook answers 503 (default 100)
+//	WEBHOOK_DEDUP_STORE  "mongo" (default), kept in db, or "memory"
+//	WEBHOOK_DEDUP_TTL    how long handled events are remembered (default 24h)
+//	WEBHOOK_DEDUP_LEASE  how long a claim lasts, and so how long an event may run (default 5m)
+func NewEventQueue(ctx context.Context, db *mongo.Database) (*EventQueue, error) {
+	opts := dedup.Options{
+		TTL:   utils.GetEnvPositiveDuration("WEBHOOK_DEDUP_TTL", 24*time.Hour),
+		Lease: utils.GetEnvPositiveDuration("WEBHOOK_DEDUP_LEASE", 5*time.Minute),
+	}
+
+	var store dedup.Store
+	if os.Getenv("WEBHOOK_DEDUP_STORE") == "memory" {
+		store = dedup.NewMemoryStore(opts)
+		log.Println("✅ Webhook dedup store: memory")
+	} else {
+		mongoStore, err := dedup.NewMongoStore(ctx, db.Collection("webhook_events"), opts)
+		if err != nil {
+			return nil, err
+		}
+		store = mongoStore
+		log.Println("✅ Webhook dedup store: mongo")
+	}
+
+	return &EventQueue{
+		pool: worker.NewPool(
+			utils.GetEnvPositiveInt("WEBHOOK_WORKERS", 4),
+			utils.GetEnvPositiveInt("WEBHOOK_QUEUE_SIZE", 100),
+		),
+		store:   store,
+		timeout: eventTimeoutFor(opts.Lease),
+	}, nil
+}
+
+// Shutdown waits for queued events to be processed.
+func (q *EventQueue) Shutdown(ctx context.Context) error {
+	return q.pool.Shutdown(ctx)
+}
+
+// enqueueEvents hands a webhook's events to the pool all at once, so a full
+// queue rejects the batch before any of it runs. Without a queue (e.g. in
+// tools that call the handler directly) the events are handled inline.
+func (h *Webhook) enqueueEvents(ctx context.Context, events []models.Event) error {
+	if h.events == nil {
+		for _, event := range events {
+			h.handleEvent(ctx, event)
+		}
+		return nil
+	}
+
+	jobs := make([]worker.Job, 0, len(events))
+	for _, event := range events {
+		jobs = append(jobs, func(ctx context.Context) {
+			h.handleEvent(ctx, event)
+		})
+	}
+	return h.events.pool.SubmitAll(jobs)
+}
+
+func (h *Webhook) handleEvent(ctx context.Context, event models.Event) {
+	if !h.claimEvent(ctx, event) {
+		return
+	}
+	log.Println("📩 Handling LINE event:", event.Type)
+
+	timeout := eventTimeoutFor(5 * time.Minute)
+	if h.events != nil {
+		timeout = h.events.timeout
+	}
+	dispatchCtx, cancel := context.WithTimeout(ctx, timeout)
+	h.dispatchEvent(dispatchCtx, event)
+	if dispatchCtx.Err() == context.DeadlineExceeded {
+		log.Printf("⚠️ Event %s timed out after %s", event.WebhookEventID, timeout)
+	}
+	cancel()
+
+	if h.events != nil && event.WebhookEventID != "" {
+		if err := h.events.store.Complete(ctx, event.WebhookEventID); err != nil {
+			log.Println("⚠️ Failed to mark event done:", event.WebhookEventID, err)
+		}
+	}
+}
+
+// claimEvent reports whether this worker should process the event. When
+// the dedup store is unavailable the event is processed anyway: answering
+// twice is better than not answering.
+func (h *Webhook) claimEvent(ctx context.Context, event models.Event) bool {
+	if h.events == nil || event.WebhookEventID == "" {
+		return true
+	}
+
+	claimed, err := h.events.store.Claim(ctx, event.WebhookEventID)
+	if err != nil {
+		log.Println("⚠️ Dedup store error, processing anyway:", event.WebhookEventID, err)
+		return true
+	}
+	if !claimed {
+		log.Printf("⏭️ Skipping already handled event %s (redelivery=%t)", event.WebhookEventID, event.DeliveryContext.IsRedelivery)
+		return false
+	}
+	if event.DeliveryContext.IsRedelivery {
+		log.Println("🔁 Processing redelivered event:", event.WebhookEventID)
+	}
+	return true
+}
+
+func (h *Webhook) dispatchEvent(ctx context.Context, event models.Event) {
+	switch event.Type {
+	case models.EventTypeJoin:
+		h.handleJoinEvent(ctx, event)
+	case models.EventTypeMemberJoined:
+		h.handleMemberJoinedEvent(ctx, event)
+	case models.EventTypeMessage:
+		h.handleMessageEvent(ctx, event)
+	case models.EventTypeLeave:
+		h.handleLeaveEvent(ctx, event)
+	case models.EventTypeFollow:
+		h.handleFollowEvent(ctx, event)
+	case models.EventTypeUnfollow:
+		h.handleUnfollowEvent(ctx, event)
+	case models.EventTypePostback:
+		h.handlePostbackEvent(ctx, event)
+	case models.EventTypeMemberLeft:
+		h.handleMemberLeftEvent(ctx, event)
+	case models.EventTypeUnsend:
+		h.handleUnsendEvent(ctx, event)
+	default:
+		log.Println("ℹ️ Unhandled LINE event:", event.Type)
+	}
+}
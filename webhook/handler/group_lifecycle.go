@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"line-chatbot-golang-langchain/repository"
+	"line-chatbot-golang-langchain/utils"
+)
+
+// groupDeleteGrace is how long a group's results are kept after the bot
+// leaves, set with GROUP_DELETE_GRACE (default 7 days).
+func groupDeleteGrace() time.Duration {
+	return utils.GetEnvPositiveDuration("GROUP_DELETE_GRACE", 7*24*time.Hour)
+}
+
+// StartGroupPurger removes groups whose grace period has ended, with their
+// members' results, every GROUP_PURGE_INTERVAL (default 1h) until ctx is
+// cancelled. The first sweep runs right away.
+func StartGroupPurger(ctx context.Context, repos *repository.Repositories) {
+	interval := utils.GetEnvPositiveDuration("GROUP_PURGE_INTERVAL", time.Hour)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			purgeExpiredGroups(ctx, repos)
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	log.Println("🧹 Group purger started, interval", interval)
+}
+
+func purgeExpiredGroups(ctx context.Context, repos *repository.Repositories) {
+	purged, err := repos.PurgeExpiredGroups(ctx, time.Now())
+	if err != nil {
+		log.Println("❌ Group purge failed:", err)
+	}
+	if purged > 0 {
+		log.Printf("🗑️ Purged %d groups and their members' results", purged)
+	}
+}
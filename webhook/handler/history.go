@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"line-chatbot-golang-langchain/linebot"
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/service"
+	"line-chatbot-golang-langchain/utils"
+)
+
+// historyCommand shows the sender how their style changed between retakes.
+const historyCommand = "ประวัติ"
+
+// historyChatLimit keeps the chat reply short; the API returns more.
+const historyChatLimit = 5
+
+// NewAssessmentHistoryHandler serves /assessment-history: the retakes of the
+// user the LIFF ID token belongs to, in the chat given by the groupid or
+// roomid header. ?limit= caps the number of retakes returned.
+func NewAssessmentHistoryHandler(svc *service.HistoryService) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", "Authorization, Groupid, Roomid")
+		w.Header().Set("Access-Control-Max-Age", "86400")
+
+		if r.Method == http.MethodOptions {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		idToken := r.Header.Get("Authorization")
+		if idToken == "" {
+			http.Error(w, "Missing Authorization header", http.StatusBadRequest)
+			return
+		}
+
+		limit := 0
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 {
+				http.Error(w, "limit must be a positive number", http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+
+		history, err := svc.ForToken(r.Context(), idToken, r.Header.Get("groupid"), r.Header.Get("roomid"), limit)
+		if err != nil {
+			if errors.Is(err, service.ErrUnauthorized) {
+				http.Error(w, "Invalid LINE ID Token", http.StatusUnauthorized)
+				return
+			}
+			log.Println("❌ Failed to load assessment history:", err)
+			http.Error(w, "Failed to load history", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(history); err != nil {
+			log.Println("⚠️ Failed to encode history:", err)
+		}
+	}
+}
+
+// replyHistory answers the history command with the sender's last retakes.
+func (h *Webhook) replyHistory(ctx context.Context, event models.Event, quoteToken string) {
+	source := *event.Source
+	liffURL := utils.LiffURL(source)
+	mention := map[string]linebot.Substitution{"user1": linebot.UserMention{UserID: source.UserID}}
+
+	history, err := h.history.ForUser(ctx, source, historyChatLimit)
+	if err != nil {
+		log.Println("❌ Failed to load assessment history:", err)
+		return
+	}
+
+	var response linebot.TextV2
+	switch len(history.Entries) {
+	case 0:
+		response = textV2("{user1} ยังไม่มีผลแบบทดสอบในแชทนี้ เริ่มทำแบบทดสอบกันเลยครับ", mention)
+	case 1:
+		response = textV2("📈 {user1} มีผลแบบทดสอบครั้งเดียว ("+history.Latest().Style+") \n ทำแบบทดสอบอีกครั้งเพื่อดูการเปลี่ยนแปลงได้เลยครับ", mention)
+	default:
+		response = textV2("📈 ประวัติผล DISC ของ {user1}\n"+service.FormatHistory(history), mention)
+	}
+	response.QuoteToken = quoteToken
+	response.QuickReply = createQuickReplyItems(liffURL, "ทำแบบทดสอบอีกครั้ง")
+	reply(ctx, event, personalize(source, response))
+}
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"context"
+	"log"
+	"net/url"
+	"strings"
+
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/utils"
+)
+
+// Postback actions carried in the data of buttons sent by the bot, encoded
+// as a query string such as "action=result".
+const (
+	postbackActionResult = "result"
+	postbackActionRetake = "retake"
+	postbackActionReset  = "reset"
+)
+
+func isCommand(text string) bool {
+	return text == "Type" || text == "วิเคราะห์" || text == historyCommand || text == teamReportCommand || strings.HasPrefix(text, "ฉันได้ประเมินเรียบร้อยแล้ว")
+}
+
+func (h *Webhook) handleFollowEvent(ctx context.Context, event models.Event) {
+	userID := event.Source.UserID
+	log.Println("🤝 New follower:", userID)
+
+	unblocked := event.Follow != nil && event.Follow.IsUnblocked
+	if err := h.repos.Members.Follow(ctx, userID, unblocked); err != nil {
+		log.Println("❌ Failed to save follower:", err)
+	}
+
+	greeting := "สวัสดีครับ ขอบคุณที่เพิ่มเราเป็นเพื่อน 🙏 \n มาทำแบบทดสอบ DISC เพื่อรู้จักสไตล์การทำงานของตัวเองกันเถอะ"
+	if unblocked {
+		greeting = "ยินดีต้อนรับกลับมาครับ 🙏 \n ทำแบบทดสอบ DISC ใหม่ หรือดูผลล่าสุดของคุณได้เลย"
+	}
+
+	message := map[string]interface{}{
+		"type":       "text",
+		"text":       greeting,
+		"quickReply": createQuickReplyItems(utils.LiffURL(*event.Source), "เริ่มทำแบบทดสอบ"),
+	}
+	reply(ctx, event, message)
+	log.Println("✅ Sent follow greeting to:", userID)
+}
+
+func (h *Webhook) handleUnfollowEvent(ctx context.Context, event models.Event) {
+	userID := event.Source.UserID
+	log.Println("🚪 User unfollowed:", userID)
+
+	if err := h.repos.Members.Unfollow(ctx, userID); err != nil {
+		log.Println("❌ Failed to mark user inactive:", err)
+		return
+	}
+	log.Println("✅ User marked inactive:", userID)
+}
+
+func (h *Webhook) handlePostbackEvent(ctx context.Context, event models.Event) {
+	source := *event.Source
+	data, err := url.ParseQuery(event.Postback.Data)
+	if err != nil {
+		log.Println("🚫 Invalid postback data:", event.Postback.Data)
+		return
+	}
+
+	action := data.Get("action")
+	log.Printf("🔘 Postback %q from %s: %s", action, source.Type, source.ChatID())
+
+	switch action {
+	case postbackActionResult:
+		h.replyUserResult(ctx, event, "")
+	case postbackActionRetake:
+		message := map[string]interface{}{
+			"type":       "text",
+			"text":       "เริ่มทำแบบทดสอบใหม่ได้เลยครับ ผลเดิมจะถูกเก็บไว้ พิมพ์ \"ประวัติ\" เพื่อดูการเปลี่ยนแปลง",
+			"quickReply": createQuickReplyItems(utils.LiffURL(source), "ทำแบบทดสอบ"),
+		}
+		reply(ctx, event, message)
+	case postbackActionReset:
+		if err := h.repos.Assessments.Purge(ctx, source.UserID, source); err != nil {
+			log.Println("❌ Failed to reset answers:", err)
+			return
+		}
+		message := map[string]interface{}{
+			"type":       "text",
+			"text":       "ลบผลแบบทดสอบของคุณในแชทนี้เรียบร้อยแล้วครับ",
+			"quickReply": createQuickReplyItems(utils.LiffURL(source), "ทำแบบทดสอบ"),
+		}
+		reply(ctx, event, message)
+	default:
+		log.Println("⚠️ Unknown postback action:", action)
+	}
+}
+
+func (h *Webhook) handleMemberLeftEvent(ctx context.Context, event models.Event) {
+	source := *event.Source
+	for _, member := range event.Left.Members {
+		if member.Type != models.SourceTypeUser {
+			continue
+		}
+		log.Printf("👋 Member %s left %s: %s", member.UserID, source.Type, source.ChatID())
+
+		if err := h.repos.Assessments.Delete(ctx, member.UserID, source); err != nil {
+			log.Println("❌ Failed to remove member answers:", err)
+			continue
+		}
+		log.Println("✅ Removed member from team analysis:", member.UserID)
+	}
+}
+
+func (h *Webhook) handleUnsendEvent(ctx context.Context, event models.Event) {
+	messageID := event.Unsend.MessageID
+	log.Println("↩️ Message unsent:", messageID)
+
+	if err := h.repos.Messages.Delete(ctx, messageID); err != nil {
+		log.Println("❌ Failed to delete unsent message:", err)
+		return
+	}
+	log.Println("✅ Unsent message removed:", messageID)
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"context"
+	"log"
+
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/utils"
+)
+
+// reply answers an event through the shared Messaging API client. It uses
+// the event's reply token first and pushes to the source chat when the
+// token is spent, expired, or there are more messages than one reply holds.
+func reply(ctx context.Context, event models.Event, messages ...interface{}) {
+	if len(messages) == 0 {
+		return
+	}
+
+	to := ""
+	if event.Source != nil {
+		to = event.Source.ChatID()
+	}
+
+	log.Println("📤 กำลังส่งข้อความกลับไปยัง LINE Messaging API...")
+	session := utils.LineBot().NewSession(event.ReplyToken, to)
+	if err := session.Send(ctx, messages...); err != nil {
+		log.Println("❌ Reply failed:", err)
+		return
+	}
+	log.Println("✅ ส่งข้อความสำเร็จแล้ว")
+}
@@ -1,18 +1,42 @@
 package handler
 
 import (
-	"encoding/json"
-	"fmt"
+	"context"
 	"io"
 	"log"
 	"net/http"
-	"os"
 	"strings"
 
+	"line-chatbot-golang-langchain/linebot"
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/repository"
+	"line-chatbot-golang-langchain/service"
+	"line-chatbot-golang-langchain/team"
 	"line-chatbot-golang-langchain/utils"
 )
 
-func LineWebhookHandler(w http.ResponseWriter, req *http.Request) {
+// Webhook handles the LINE webhook with the repositories and services it
+// was built with.
+type Webhook struct {
+	repos       *repository.Repositories
+	history     *service.HistoryService
+	teamReports *service.TeamReportService
+	// events is nil when events are handled inline.
+	events *EventQueue
+}
+
+func NewWebhook(repos *repository.Repositories, kb *utils.Knowledge, events *EventQueue) *Webhook {
+	return &Webhook{
+		repos:       repos,
+		history:     service.NewHistoryService(repos),
+		teamReports: service.NewTeamReportService(repos, kb),
+		events:      events,
+	}
+}
+
+// ServeHTTP verifies and acknowledges a webhook; its events are handled by
+// the worker pool.
+func (h *Webhook) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	signature := req.Header.Get("X-Line-Signature")
 	if signature == "" {
 		http.Error(w, "Missing Signature", http.StatusUnauthorized)
@@ -33,52 +57,46 @@ func LineWebhookHandler(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	var payload map[string]interface{}
-	if err := json.Unmarshal(body, &payload); err != nil {
+	payload, err := models.ParseWebhookRequest(body)
+	if err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
-		log.Println("🚫 Invalid JSON payload:", err)
+		log.Println("🚫 Invalid webhook payload:", err)
 		return
 	}
 
-	events := payload["events"].([]interface{})
-	for _, e := range events {
-		event := e.(map[string]interface{})
-		eventType := event["type"].(string)
-		log.Println("📩 Handling LINE event:", eventType)
-
-		switch eventType {
-		case "join":
-			handleJoinEvent(event)
-		case "memberJoined":
-			handleMemberJoinedEvent(event)
-		case "message":
-			handleMessageEvent(event, w)
-		case "leave":
-			handleLeaveEvent(event)
-		}
+	if err := h.enqueueEvents(req.Context(), payload.Events); err != nil {
+		// LINE redelivers the webhook when it gets a non-2xx response. Nothing
+		// from the batch was queued, so the redelivery is not a duplicate.
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "Server Busy", http.StatusServiceUnavailable)
+		log.Printf("⚠️ Webhook queue rejected %d events: %v", len(payload.Events), err)
+		return
 	}
 
 	w.WriteHeader(http.StatusOK)
-	log.Println("✅ Webhook processed successfully")
+	log.Printf("✅ Webhook accepted %d events", len(payload.Events))
 }
 
-func handleJoinEvent(event map[string]interface{}) {
-	source := event["source"].(map[string]interface{})
-	groupID := source["groupId"].(string)
-	replyToken := event["replyToken"].(string)
+func (h *Webhook) handleJoinEvent(ctx context.Context, event models.Event) {
+	chatID := event.Source.ChatID()
 
-	log.Println("👥 Bot joined group:", groupID)
+	log.Printf("👥 Bot joined %s: %s", event.Source.Type, chatID)
 
-	liffURL := os.Getenv("LINE_LIFF_DISC") + "?groupId=" + groupID
+	liffURL := utils.LiffURL(*event.Source)
 
-	// if err := utils.UpsertGroup(groupID); err != nil {
-	// 	log.Println("❌ Failed to upsert group:", err)
-	// 	return
-	// }
+	greeting := "สวัสดีทุกค๊นน มารวมกันทำแบบสอบถามกันเถอะ \r\n หากต้องการเริ่มทำแบบสอบถามใหม่ \n เพียง tag ชื่อ @disc ได้เลย "
+	// A failed registration only means the group is not tracked; still greet.
+	restored, err := h.repos.Groups.Register(ctx, *event.Source)
+	if err != nil {
+		log.Println("❌ Failed to register group:", err)
+	} else if restored {
+		log.Printf("♻️ Restored %s: %s", event.Source.Type, chatID)
+		greeting = "ยินดีที่ได้กลับมาครับ 🙏 ผลแบบทดสอบของทุกคนยังอยู่ครบ \n พิมพ์ วิเคราะห์ เพื่อดูภาพรวมทีมได้เลย"
+	}
 
 	message := map[string]interface{}{
 		"type": "text",
-		"text": "สวัสดีทุกค๊นน มารวมกันทำแบบสอบถามกันเถอะ \r\n หากต้องการเริ่มทำแบบสอบถามใหม่ \n เพียง tag ชื่อ @disc ได้เลย ",
+		"text": greeting,
 		"quickReply": map[string]interface{}{
 			"items": []interface{}{
 				map[string]interface{}{
@@ -101,298 +119,215 @@ func handleJoinEvent(event map[string]interface{}) {
 		},
 	}
 
-	utils.ReplyMessage(replyToken, []interface{}{message})
-	log.Println("✅ Sent join message to group:", groupID)
+	reply(ctx, event, message)
+	log.Printf("✅ Sent join message to %s: %s", event.Source.Type, chatID)
 }
 
-func handleMemberJoinedEvent(event map[string]interface{}) {
-	replyToken := event["replyToken"].(string)
-	groupID := event["source"].(map[string]interface{})["groupId"].(string)
-	liffURL := os.Getenv("LINE_LIFF_DISC") + "?groupId=" + groupID
-	members := event["joined"].(map[string]interface{})["members"].([]interface{})
+func (h *Webhook) handleMemberJoinedEvent(ctx context.Context, event models.Event) {
+	source := *event.Source
+	liffURL := utils.LiffURL(source)
 
-	for _, m := range members {
-		member := m.(map[string]interface{})
-		if member["type"] != "user" {
+	var messages []interface{}
+	for _, member := range event.Joined.Members {
+		if member.Type != models.SourceTypeUser {
 			continue
 		}
-		userID := member["userId"].(string)
-
-		message := map[string]interface{}{
-			"type": "textV2",
-			"text": "สวัสดีคุณ {user1}! ยินดีต้อนรับ \n ทุกคน {everyone} มีเพื่อนใหม่เข้ามาอย่าลืมทักทายกันนะ!",
-			"quickReply": map[string]interface{}{
-				"items": []interface{}{
-					map[string]interface{}{
-						"type": "action",
-						"action": map[string]interface{}{
-							"type":  "uri",
-							"label": "เริ่มทำแบบทดสอบ",
-							"uri":   liffURL,
-						},
-					},
-					map[string]interface{}{
-						"type": "action",
-						"action": map[string]interface{}{
-							"type":  "message",
-							"label": "Type",
-							"text":  "Type",
-						},
-					},
-				},
-			},
-			"substitution": map[string]interface{}{
-				"user1": map[string]interface{}{
-					"type": "mention",
-					"mentionee": map[string]interface{}{
-						"type":   "user",
-						"userId": userID,
-					},
-				},
-				"everyone": map[string]interface{}{
-					"type": "mention",
-					"mentionee": map[string]interface{}{
-						"type": "all",
-					},
-				},
-			},
-		}
-		utils.ReplyMessage(replyToken, []interface{}{message})
-		log.Println("✅ Welcomed new member:", userID)
+		userID := member.UserID
+
+		message := textV2("สวัสดีคุณ {user1}! ยินดีต้อนรับ \n ทุกคน {everyone} มีเพื่อนใหม่เข้ามาอย่าลืมทักทายกันนะ!", map[string]linebot.Substitution{
+			"user1":    linebot.UserMention{UserID: userID},
+			"everyone": linebot.AllMention{},
+		})
+		message.QuickReply = createQuickReplyItems(liffURL, "เริ่มทำแบบทดสอบ")
+		messages = append(messages, personalize(source, message))
+		log.Println("👋 Welcoming new member:", userID)
 	}
-}
 
-func handleMessageEvent(event map[string]interface{}, w http.ResponseWriter) {
-	message := event["message"].(map[string]interface{})
-	text := message["text"].(string)
-	replyToken := event["replyToken"].(string)
-	source := event["source"].(map[string]interface{})
-	groupID := source["groupId"].(string)
-	userID := source["userId"].(string)
+	// One reply token covers every member who joined; the dispatcher pushes
+	// whatever does not fit in the reply.
+	reply(ctx, event, messages...)
+}
 
-	if text == "ฉันได้ประเมินเรียบร้อยแล้ว" || text == "Type" {
-		userData, err := utils.GetAnswersByUserID(userID, groupID)
-		if err != nil {
-			log.Println("❌ Failed to get user answers:", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
+func (h *Webhook) handleMessageEvent(ctx context.Context, event models.Event) {
+	message := event.Message
+	if message.Type != models.MessageTypeText {
+		log.Println("ℹ️ Ignoring non-text message:", message.Type)
+		return
+	}
+	text := message.Text
+	source := *event.Source
+	userID := source.UserID
+	liffURL := utils.LiffURL(source)
+
+	if isCommand(text) {
+		if err := h.repos.Messages.Save(ctx, message.ID, source, text); err != nil {
+			log.Println("❌ Failed to save command message:", err)
 		}
+	}
 
-		liffURL := os.Getenv("LINE_LIFF_DISC") + "?groupId=" + groupID
-
-		var response map[string]interface{}
-		if userData != nil {
-			response = map[string]interface{}{
-				"type":       "textV2",
-				"text":       fmt.Sprintf("คุณ {user1} คุณอยู่ในกลุ่ม %s \r\n\r\n รายละเอียด %s", userData["model"], userData["description"]),
-				"quoteToken": message["quoteToken"],
-				"quickReply": createQuickReplyItems(liffURL),
-				"substitution": map[string]interface{}{
-					"user1": map[string]interface{}{
-						"type": "mention",
-						"mentionee": map[string]interface{}{
-							"type":   "user",
-							"userId": userID,
-						},
-					},
-				},
-			}
-		} else {
-			response = map[string]interface{}{
-				"type":       "textV2",
-				"text":       "สวัสดีครับ {user1} เรามาเริ่มทำแบบทดสอบกันดีกว่า",
-				"quoteToken": message["quoteToken"],
-				"quickReply": createQuickReplyItems(liffURL),
-				"substitution": map[string]interface{}{
-					"user1": map[string]interface{}{
-						"type": "mention",
-						"mentionee": map[string]interface{}{
-							"type":   "user",
-							"userId": userID,
-						},
-					},
-				},
-			}
-		}
+	if strings.HasPrefix(text, "ฉันได้ประเมินเรียบร้อยแล้ว") || text == "Type" {
+		h.replyUserResult(ctx, event, message.QuoteToken)
+	}
 
-		utils.ReplyMessage(replyToken, []interface{}{response})
+	if text == historyCommand {
+		h.replyHistory(ctx, event, message.QuoteToken)
 	}
 
 	if text == "วิเคราะห์" {
-		userList, err := utils.GetAllUsersInGroup(groupID)
-		fmt.Println("userList", userList)
+		h.replyTeamSummary(ctx, event)
+	}
 
-		if err != nil {
-			log.Println("❌ Failed to get users in group:", err)
-			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-			return
-		}
-		if len(userList) == 0 {
-			log.Println("⚠️ No user data found for group:", groupID)
-			utils.ReplyMessage(replyToken, []interface{}{
-				map[string]interface{}{
-					"type": "text",
-					"text": "ไม่พบข้อมูลของผู้ใช้ในกลุ่มนี้ โปรดทำแบบทดสอบก่อนนะครับ 🙏",
-				},
+	if text == teamReportCommand {
+		h.replyTeamReport(ctx, event, message.QuoteToken)
+	}
+
+	if message.MentionsSelf() || message.MentionsAll() {
+		response := textV2("ว่ายังไงครับ ถามได้เลย", nil)
+		if message.MentionsSelf() && userID != "" {
+			response = textV2("ว่ายังไงครับ ถามได้เลย {user1}", map[string]linebot.Substitution{
+				"user1": linebot.UserMention{UserID: userID},
 			})
-			return
 		}
+		response.QuoteToken = message.QuoteToken
+		response.QuickReply = createQuickReplyItems(liffURL, "เริ่มทำแบบทดสอบ")
+		reply(ctx, event, personalize(source, response))
+	}
+}
 
-		// ✅ เตรียมข้อความและแท็ก mention
-		var contentBuilder strings.Builder
-		substitution := map[string]interface{}{}
-		count := map[string]int{"D": 0, "I": 0, "S": 0, "C": 0}
-
-		fmt.Println("count")
-
-		for idx, user := range userList {
+// replyTeamSummary lists every member's DISC type with a mention, followed
+// by the team analysis, split over several messages when the group is too
+// large for one.
+func (h *Webhook) replyTeamSummary(ctx context.Context, event models.Event) {
+	source := *event.Source
+	liffURL := utils.LiffURL(source)
+
+	if !source.IsMultiPerson() {
+		reply(ctx, event, map[string]interface{}{
+			"type": "text",
+			"text": "คำสั่งวิเคราะห์ใช้ได้ในกลุ่มหรือห้องแชทเท่านั้นครับ 🙏",
+		})
+		return
+	}
 
-			fmt.Println(user)
+	userList, err := h.repos.Assessments.ListByChat(ctx, source)
+	if err != nil {
+		log.Println("❌ Failed to get users in chat:", err)
+		return
+	}
+	if len(userList) == 0 {
+		log.Printf("⚠️ No user data found for %s: %s", source.Type, source.ChatID())
+		reply(ctx, event, map[string]interface{}{
+			"type": "text",
+			"text": "ไม่พบข้อมูลของผู้ใช้ในกลุ่มนี้ โปรดทำแบบทดสอบก่อนนะครับ 🙏",
+		})
+		return
+	}
 
-			userID := user["userId"].(string)
-			model := user["model"].(string)
-			mentionKey := fmt.Sprintf("user%d", idx)
+	// ✅ เตรียมข้อความและแท็ก mention
+	builder := linebot.NewTextV2Builder()
+	members := make([]team.Member, 0, len(userList))
 
-			count[string(model[0])]++
-			contentBuilder.WriteString(fmt.Sprintf("- {%s} อยู่ในกลุ่ม %s\n", mentionKey, model))
+	results := make([]memberResult, 0, len(userList))
 
-			substitution[mentionKey] = map[string]interface{}{
-				"type": "mention",
-				"mentionee": map[string]interface{}{
-					"type":   "user",
-					"userId": userID,
-				},
-			}
+	for _, user := range userList {
+		members = append(members, team.Member{UserID: user.UserID, Style: user.Model, Scores: user.Scores})
+		if len(results) < maxCarouselBubbles {
+			results = append(results, memberResult{
+				Name:        memberName(ctx, source, user.UserID),
+				Model:       user.Model,
+				Description: user.Description,
+			})
+		}
+		if err := builder.Line("- {user} "+resultLabel(user.Model), map[string]linebot.Substitution{
+			"user": linebot.UserMention{UserID: user.UserID},
+		}); err != nil {
+			log.Println("⚠️ Skipping member line:", user.UserID, err)
 		}
+	}
 
-		// ✅ สรุปและคำแนะนำ
-		contentBuilder.WriteString("\n👥 สรุปจำนวน DISC:\n")
-		contentBuilder.WriteString(fmt.Sprintf("D: %d | I: %d | S: %d | C: %d\n", count["D"], count["I"], count["S"], count["C"]))
-
-		contentBuilder.WriteString("\n📌 แนะนำการจับคู่ DISC ที่ทำงานเข้ากันได้:\n")
-		contentBuilder.WriteString("- D + I: เด็ดขาด + สื่อสารเก่ง\n")
-		contentBuilder.WriteString("- D + C: ตัดสินใจไว + วิเคราะห์เก่ง\n")
-		contentBuilder.WriteString("- I + S: บรรยากาศดี + ทีมเวิร์ค\n")
-		contentBuilder.WriteString("- S + C: มั่นคง + ละเอียด\n")
-
-		fmt.Println(contentBuilder)
-
-		// ✅ ส่งข้อความ reply แบบ textV2 พร้อม mention
-		message := map[string]interface{}{
-			"type":         "textV2",
-			"text":         contentBuilder.String(),
-			"substitution": substitution,
-			"quickReply": map[string]interface{}{
-				"items": []interface{}{
-					map[string]interface{}{
-						"type": "action",
-						"action": map[string]interface{}{
-							"type":  "uri",
-							"label": "ทำแบบสอบถาม",
-							"uri":   os.Getenv("LINE_LIFF_DISC") + "?groupId=" + groupID,
-						},
-					},
-				},
-			},
+	// ✅ สรุปและคำแนะนำ
+	writeTeamReport(builder, team.Analyze(members))
+
+	// ✅ ส่งข้อความ reply แบบ textV2 พร้อม mention
+	built := builder.Messages()
+	quickReply := createQuickReplyItems(liffURL, "ทำแบบสอบถาม")
+	quickReply["items"] = append(quickReply["items"].([]interface{}), map[string]interface{}{
+		"type": "action",
+		"action": map[string]interface{}{
+			"type":  "message",
+			"label": teamReportCommand,
+			"text":  teamReportCommand,
+		},
+	})
+	messages := make([]interface{}, 0, len(built)+1)
+	for _, m := range built {
+		messages = append(messages, m)
+	}
+	carousel := teamResultCarousel(results, liffURL)
+	carousel.QuickReply = quickReply
+	messages = append(messages, carousel)
+	reply(ctx, event, messages...)
+}
+
+// memberName is the member's display name in the chat, or a generic label
+// when LINE does not return their profile.
+func memberName(ctx context.Context, source models.Source, userID string) string {
+	var profile *linebot.Profile
+	var err error
+	switch source.Type {
+	case models.SourceTypeGroup:
+		profile, err = utils.LineBot().GetGroupMemberProfile(ctx, source.GroupID, userID)
+	case models.SourceTypeRoom:
+		profile, err = utils.LineBot().GetRoomMemberProfile(ctx, source.RoomID, userID)
+	}
+	if err != nil || profile == nil || profile.DisplayName == "" {
+		if err != nil {
+			log.Println("⚠️ Failed to get member profile:", userID, err)
 		}
+		return "สมาชิก"
+	}
+	return profile.DisplayName
+}
 
-		fmt.Println(message)
+// replyUserResult answers with the user's stored DISC result in this chat,
+// or invites them to take the test when there is none yet.
+func (h *Webhook) replyUserResult(ctx context.Context, event models.Event, quoteToken string) {
+	source := *event.Source
+	userID := source.UserID
+	liffURL := utils.LiffURL(source)
 
-		utils.ReplyMessage(replyToken, []interface{}{message})
+	userData, err := h.repos.Assessments.Get(ctx, userID, source)
+	if err != nil {
+		log.Println("❌ Failed to get user answers:", err)
+		return
 	}
 
-	mentionees, ok := event["message"].(map[string]interface{})["mention"].(map[string]interface{})["mentionees"].([]interface{})
-	if ok {
-		liffURL := os.Getenv("LINE_LIFF_DISC") + "?groupId=" + groupID
-		for _, mentionee := range mentionees {
-			mentioneeMap := mentionee.(map[string]interface{})
-
-			fmt.Println("--------------------------------")
-			fmt.Println(mentioneeMap)
-			fmt.Println("--------------------------------")
-			if isSelfVal, ok := mentioneeMap["isSelf"]; ok && isSelfVal != nil {
-				if isSelf, ok := isSelfVal.(bool); ok && isSelf {
-					// ทำงานต่อเมื่อ isSelf เป็น true
-					if mentioneeMap["isSelf"].(bool) {
-						response := map[string]interface{}{
-							"type":       "textV2",
-							"text":       "ว่ายังไงครับ ถามได้เลย {user1}",
-							"quoteToken": event["message"].(map[string]interface{})["quoteToken"],
-							"substitution": map[string]interface{}{
-								"user1": map[string]interface{}{
-									"type": "mention",
-									"mentionee": map[string]interface{}{
-										"type":   "user",
-										"userId": userID,
-									},
-								},
-							},
-							"quickReply": map[string]interface{}{
-								"items": []interface{}{
-									map[string]interface{}{
-										"type": "action",
-										"action": map[string]interface{}{
-											"type":  "uri",
-											"label": "เริ่มทำแบบทดสอบ",
-											"uri":   liffURL,
-										},
-									},
-									map[string]interface{}{
-										"type": "action",
-										"action": map[string]interface{}{
-											"type":  "message",
-											"label": "Type",
-											"text":  "Type",
-										},
-									},
-								},
-							},
-						}
-						utils.ReplyMessage(replyToken, []interface{}{response})
-					}
-				}
-			}
-			if mentioneeMap["type"].(string) == "all" {
-				response := map[string]interface{}{
-					"type":       "textV2",
-					"text":       "ว่ายังไงครับ ถามได้เลย",
-					"quoteToken": event["message"].(map[string]interface{})["quoteToken"],
-					"quickReply": map[string]interface{}{
-						"items": []interface{}{
-							map[string]interface{}{
-								"type": "action",
-								"action": map[string]interface{}{
-									"type":  "uri",
-									"label": "เริ่มทำแบบทดสอบ",
-									"uri":   liffURL,
-								},
-							},
-							map[string]interface{}{
-								"type": "action",
-								"action": map[string]interface{}{
-									"type":  "message",
-									"label": "Type",
-									"text":  "Type",
-								},
-							},
-						},
-					},
-				}
-				utils.ReplyMessage(replyToken, []interface{}{response})
-			}
-		}
+	mention := map[string]linebot.Substitution{"user1": linebot.UserMention{UserID: userID}}
+
+	if userData != nil {
+		response := textV2("{user1} "+resultLabel(userData.Model), mention)
+		response.QuoteToken = quoteToken
+
+		card := discResultCard(userData.Model, userData.Description, liffURL)
+		card.QuickReply = createQuickReplyItems(liffURL, "ทำแบบทดสอบ")
+		reply(ctx, event, personalize(source, response), card)
+		return
 	}
 
+	response := textV2("สวัสดีครับ {user1} เรามาเริ่มทำแบบทดสอบกันดีกว่า", mention)
+	response.QuoteToken = quoteToken
+	response.QuickReply = createQuickReplyItems(liffURL, "ทำแบบทดสอบ")
+	reply(ctx, event, personalize(source, response))
 }
 
-func createQuickReplyItems(liffURL string) map[string]interface{} {
+func createQuickReplyItems(liffURL, label string) map[string]interface{} {
 	return map[string]interface{}{
 		"items": []interface{}{
 			map[string]interface{}{
 				"type": "action",
 				"action": map[string]interface{}{
 					"type":  "uri",
-					"label": "ทำแบบทดสอบ",
+					"label": label,
 					"uri":   liffURL,
 				},
 			},
@@ -408,13 +343,37 @@ func createQuickReplyItems(liffURL string) map[string]interface{} {
 	}
 }
 
-func handleLeaveEvent(event map[string]interface{}) {
-	groupID := event["source"].(map[string]interface{})["groupId"].(string)
-	log.Println("👋 Bot left group:", groupID)
+// handleLeaveEvent soft-deletes the group; its members' results are purged
+// once the grace period ends unless the bot is invited back.
+func (h *Webhook) handleLeaveEvent(ctx context.Context, event models.Event) {
+	groupID := event.Source.ChatID()
+	log.Printf("👋 Bot left %s: %s", event.Source.Type, groupID)
 
-	if err := utils.DeleteGroup(groupID); err != nil {
+	grace := groupDeleteGrace()
+	if err := h.repos.Groups.SoftDelete(ctx, *event.Source, grace); err != nil {
 		log.Println("❌ Failed to delete group:", err)
 		return
 	}
-	log.Println("✅ Group deleted successfully")
+	log.Printf("✅ Group marked deleted, results purged after %s: %s", grace, groupID)
+}
+
+// textV2 builds a reply from a fixed template. The templates live in this
+// file, so an invalid one is a bug: it is logged and sent as plain text.
+func textV2(text string, subs map[string]linebot.Substitution) linebot.TextV2 {
+	message, err := linebot.NewTextV2(text, subs)
+	if err != nil {
+		log.Println("❌ Invalid textV2 message:", err)
+		return linebot.TextV2{Text: text}
+	}
+	return message
+}
+
+// personalize adapts a textV2 reply to the chat it is sent to. LINE only
+// accepts mentions in groups and rooms, so in a 1:1 chat mentions become
+// plain words.
+func personalize(source models.Source, message linebot.TextV2) linebot.TextV2 {
+	if source.IsMultiPerson() {
+		return message
+	}
+	return message.WithoutMentions("คุณ", "ทุกคน")
 }
@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"line-chatbot-golang-langchain/questionnaire"
+)
+
+// QuestionnaireHandler serves the DISC question bank to the LIFF app.
+// Query parameters: version (defaults to the active one) and locale.
+func QuestionnaireHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
+	w.Header().Set("Access-Control-Max-Age", "86400")
+
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q, err := questionnaire.Resolve(r.URL.Query().Get("version"))
+	if err != nil {
+		log.Println("🚫 Questionnaire lookup failed:", err)
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	locale := r.URL.Query().Get("locale")
+	if locale == "" {
+		locale = questionnaire.DefaultLocale
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(q.Localize(locale)); err != nil {
+		log.Println("⚠️ Failed to encode questionnaire:", err)
+	}
+}
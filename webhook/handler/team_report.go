@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"line-chatbot-golang-langchain/linebot"
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/scoring"
+	"line-chatbot-golang-langchain/service"
+	"line-chatbot-golang-langchain/team"
+	"line-chatbot-golang-langchain/utils"
+)
+
+// teamReportCommand asks for LLM coaching for the whole team.
+const teamReportCommand = "รายงานทีม"
+
+// teamReportPairs caps the pairs and frictions listed in chat; the report
+// itself keeps all of them.
+const teamReportPairs = 3
+
+// writeTeamReport renders the team analysis below the member list.
+func writeTeamReport(builder *linebot.TextV2Builder, report team.Report) {
+	line := func(template string, subs map[string]linebot.Substitution) {
+		if err := builder.Line(template, subs); err != nil {
+			log.Println("⚠️ Skipping team report line:", err)
+		}
+	}
+
+	line("", nil)
+	line("👥 สรุปจำนวน DISC:", nil)
+	line(formatCounts(report.Primary), nil)
+	if !sameCounts(report.Primary, report.Presence) {
+		line("นับรวมแบบผสม: "+formatCounts(report.Presence), nil)
+	}
+	line("📊 สัดส่วนเฉลี่ยของทีม: "+service.FormatScores(scoring.Result{Scores: report.Average}), nil)
+	line(fmt.Sprintf("⚖️ ความสมดุลของทีม: %.0f%%", report.Balance*100), nil)
+
+	for _, style := range report.Dominant {
+		line(fmt.Sprintf("🔥 %s เด่นในทีม (%.0f%%): %s", style.Style, style.Share, style.Note), nil)
+	}
+	for _, style := range report.Missing {
+		line(fmt.Sprintf("🕳️ ไม่มี %s ในทีม: %s", style.Style, style.Note), nil)
+	}
+
+	var good []team.Pair
+	for _, pair := range report.Pairs {
+		if pair.Level == team.LevelGood && len(good) < teamReportPairs {
+			good = append(good, pair)
+		}
+	}
+	if len(good) > 0 {
+		line("", nil)
+		line("🤝 คู่ที่ทำงานเข้ากันได้ดี:", nil)
+		for _, pair := range good {
+			line(fmt.Sprintf("- {a} + {b} (%s/%s) %.0f%%: %s", pair.StyleA, pair.StyleB, pair.Score*100, pair.Note), pairMentions(pair.A, pair.B))
+		}
+	}
+
+	if len(report.Frictions) > 0 {
+		line("", nil)
+		line("⚠️ จุดที่อาจเกิดแรงเสียดทาน:", nil)
+		for i, friction := range report.Frictions {
+			if i == teamReportPairs {
+				break
+			}
+			if friction.Kind == team.FrictionPairKind {
+				line(fmt.Sprintf("- {a} + {b} (%s): %s", friction.Style, friction.Note), pairMentions(friction.Members[0], friction.Members[1]))
+				continue
+			}
+			line(fmt.Sprintf("- มี %s %d คน: %s", friction.Style, len(friction.Members), friction.Note), nil)
+		}
+	}
+}
+
+func pairMentions(a, b string) map[string]linebot.Substitution {
+	return map[string]linebot.Substitution{
+		"a": linebot.UserMention{UserID: a},
+		"b": linebot.UserMention{UserID: b},
+	}
+}
+
+// formatCounts renders counts as "D: 2 | I: 1 | S: 0 | C: 1".
+func formatCounts(counts map[string]int) string {
+	parts := make([]string, 0, len(scoring.Dimensions))
+	for _, dim := range scoring.Dimensions {
+		parts = append(parts, fmt.Sprintf("%s: %d", dim, counts[dim]))
+	}
+	return strings.Join(parts, " | ")
+}
+
+func sameCounts(a, b map[string]int) bool {
+	for _, dim := range scoring.Dimensions {
+		if a[dim] != b[dim] {
+			return false
+		}
+	}
+	return true
+}
+
+// replyTeamReport answers the team report command with coaching written
+// for this chat's members, reused until their results change.
+func (h *Webhook) replyTeamReport(ctx context.Context, event models.Event, quoteToken string) {
+	source := *event.Source
+	liffURL := utils.LiffURL(source)
+
+	if !source.IsMultiPerson() {
+		reply(ctx, event, map[string]interface{}{
+			"type": "text",
+			"text": "คำสั่งรายงานทีมใช้ได้ในกลุ่มหรือห้องแชทเท่านั้นครับ 🙏",
+		})
+		return
+	}
+
+	report, err := h.teamReports.ForChat(ctx, source)
+	if err != nil {
+		text := "ขออภัยครับ ตอนนี้ยังสร้างรายงานทีมไม่ได้ ลองใหม่อีกครั้งนะครับ 🙏"
+		if errors.Is(err, service.ErrNoResults) {
+			text = "ไม่พบข้อมูลของผู้ใช้ในกลุ่มนี้ โปรดทำแบบทดสอบก่อนนะครับ 🙏"
+		} else {
+			log.Println("❌ Failed to build team report:", err)
+		}
+		response := textV2(text, nil)
+		response.QuoteToken = quoteToken
+		response.QuickReply = createQuickReplyItems(liffURL, "ทำแบบสอบถาม")
+		reply(ctx, event, response)
+		return
+	}
+
+	builder := linebot.NewTextV2Builder()
+	line := func(template string, subs map[string]linebot.Substitution) {
+		if err := builder.Line(template, subs); err != nil {
+			log.Println("⚠️ Skipping team report line:", err)
+		}
+	}
+
+	// The advice is written by the LLM: it goes in as escaped text, never as
+	// a template, so a stray {placeholder} cannot break or hijack the reply.
+	literal := linebot.EscapeTextV2
+	advice := report.Coaching
+	line(fmt.Sprintf("🧭 รายงานโค้ชทีม (%d คน)", report.Analysis.Size), nil)
+	if advice.Summary != "" {
+		line(literal(advice.Summary), nil)
+	}
+	line("", nil)
+	line("💬 แนวทางการสื่อสาร:", nil)
+	for _, item := range advice.Communication {
+		line("- "+literal(item), nil)
+	}
+	line("", nil)
+	line("📅 แนวทางการประชุม:", nil)
+	for _, item := range advice.Meetings {
+		line("- "+literal(item), nil)
+	}
+	if len(advice.Roles) > 0 {
+		line("", nil)
+		line("🎯 บทบาทที่แนะนำ:", nil)
+		for _, role := range advice.Roles {
+			line("- {user} "+literal(fmt.Sprintf("(%s): %s — %s", role.Style, role.Role, role.Reason)), map[string]linebot.Substitution{
+				"user": linebot.UserMention{UserID: role.UserID},
+			})
+		}
+	}
+
+	built := builder.Messages()
+	if len(built) == 0 {
+		log.Println("⚠️ Team report rendered no text:", source.ChatID())
+		return
+	}
+	built[0].QuoteToken = quoteToken
+	built[len(built)-1].QuickReply = createQuickReplyItems(liffURL, "ทำแบบสอบถาม")
+	messages := make([]interface{}, 0, len(built))
+	for _, m := range built {
+		messages = append(messages, m)
+	}
+	reply(ctx, event, messages...)
+	log.Printf("✅ Sent team report to %s: %s (cached=%t)", source.Type, source.ChatID(), report.Cached)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"line-chatbot-golang-langchain/coaching"
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/repository"
+	"line-chatbot-golang-langchain/service"
+	"line-chatbot-golang-langchain/team"
+)
+
+// fakeLineAPI records what the handlers send to the Messaging API. The
+// shared client reads LINE_API_BASE_URL once, so one server serves the
+// whole package.
+type fakeLineAPI struct {
+	mu     sync.Mutex
+	bodies []map[string]interface{}
+}
+
+var (
+	lineAPIOnce sync.Once
+	lineAPI     = &fakeLineAPI{}
+)
+
+func (f *fakeLineAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+	f.mu.Lock()
+	f.bodies = append(f.bodies, body)
+	f.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`{"sentMessages":[{"id":"1"}]}`))
+}
+
+// sentMessages starts the fake API on first use and returns a function
+// that lists the messages sent since.
+func sentMessages(t *testing.T) func() []map[string]interface{} {
+	t.Helper()
+	lineAPIOnce.Do(func() {
+		server := httptest.NewServer(lineAPI)
+		os.Setenv("LINE_API_BASE_URL", server.URL+"/")
+	})
+	lineAPI.mu.Lock()
+	lineAPI.bodies = nil
+	lineAPI.mu.Unlock()
+
+	return func() []map[string]interface{} {
+		lineAPI.mu.Lock()
+		defer lineAPI.mu.Unlock()
+		var out []map[string]interface{}
+		for _, body := range lineAPI.bodies {
+			for _, m := range body["messages"].([]interface{}) {
+				out = append(out, m.(map[string]interface{}))
+			}
+		}
+		return out
+	}
+}
+
+// The coaching text comes from the LLM; braces in it must reach the chat
+// as literal text rather than be read as textV2 placeholders.
+func TestReplyTeamReportEscapesAdvice(t *testing.T) {
+	sent := sentMessages(t)
+	h := &Webhook{teamReports: &service.TeamReportService{
+		LoadAssessments: func(ctx context.Context, source models.Source) ([]repository.Assessment, error) {
+			return []repository.Assessment{{UserID: "U1", Model: "D"}, {UserID: "U2", Model: "S"}}, nil
+		},
+		Coach: func(ctx context.Context, analysis team.Report, members []team.Member) (coaching.Report, error) {
+			return coaching.Report{
+				Summary:       "ทีมนี้ใช้ {x} ได้ดี",
+				Communication: []string{"ตั้ง {goal} ให้ชัด"},
+				Meetings:      []string{"ปิดท้ายด้วย {{everyone}}"},
+				Roles:         []coaching.Role{{UserID: "U1", Style: "D", Role: "{lead}", Reason: "เด็ดขาด"}},
+			}, nil
+		},
+		LoadCached: func(ctx context.Context, chatID string) (*repository.TeamReport, error) { return nil, nil },
+		SaveCached: func(ctx context.Context, report *repository.TeamReport) error { return nil },
+	}}
+	event := models.Event{
+		ReplyToken: "reply-1",
+		Source:     &models.Source{Type: models.SourceTypeGroup, GroupID: "G1", UserID: "U9"},
+	}
+
+	h.replyTeamReport(context.Background(), event, "quote-1")
+
+	messages := sent()
+	if len(messages) == 0 {
+		t.Fatal("no reply sent")
+	}
+	var text strings.Builder
+	keys := map[string]bool{}
+	for _, m := range messages {
+		if m["type"] != "textV2" {
+			t.Fatalf("message type %v, want textV2", m["type"])
+		}
+		text.WriteString(m["text"].(string))
+		subs, _ := m["substitution"].(map[string]interface{})
+		for key := range subs {
+			keys[key] = true
+		}
+	}
+
+	for _, want := range []string{
+		"ทีมนี้ใช้ {{x}} ได้ดี",
+		"- ตั้ง {{goal}} ให้ชัด",
+		"- ปิดท้ายด้วย {{{{everyone}}}}",
+		"(D): {{lead}} — เด็ดขาด",
+	} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("reply is missing %q:\n%s", want, text.String())
+		}
+	}
+	if len(keys) != 1 {
+		t.Errorf("substitutions = %v, want only the mention of U1", keys)
+	}
+}
@@ -0,0 +1,108 @@
+package ingest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/vectorstore"
+
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/textsplitter"
+)
+
+// Metadata keys set on every chunk, besides vectorstore.MetadataSource.
+const (
+	MetadataSection  = "section"
+	MetadataDiscType = "discType"
+	MetadataLanguage = "language"
+	MetadataHash     = "hash"
+	MetadataChunk    = "chunk"
+)
+
+// Hash is the content hash chunks are deduplicated by.
+func Hash(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// chunk splits each section and tags the pieces with their metadata.
+func chunk(s Source, chunking Chunking, sections []section) ([]schema.Document, error) {
+	splitter := textsplitter.NewRecursiveCharacter(
+		textsplitter.WithChunkSize(chunking.ChunkSize),
+		textsplitter.WithChunkOverlap(chunking.overlap()),
+	)
+
+	var docs []schema.Document
+	for _, sec := range sections {
+		pieces, err := splitter.SplitText(sec.Text)
+		if err != nil {
+			return nil, err
+		}
+		for _, piece := range pieces {
+			piece = strings.TrimSpace(piece)
+			if piece == "" {
+				continue
+			}
+			metadata := map[string]any{
+				vectorstore.MetadataSource: s.ID,
+				MetadataSection:            sec.Title,
+				MetadataLanguage:           firstNonEmpty(s.Language, detectLanguage(piece)),
+				MetadataHash:               Hash(piece),
+				MetadataChunk:              len(docs),
+			}
+			if disc := firstNonEmpty(s.DiscType, detectDiscType(sec.Title+"\n"+piece)); disc != "" {
+				metadata[MetadataDiscType] = disc
+			}
+			docs = append(docs, schema.Document{PageContent: piece, Metadata: metadata})
+		}
+	}
+	return docs, nil
+}
+
+// detectLanguage tells Thai from English by the share of Thai letters.
+func detectLanguage(text string) string {
+	var thai, letters int
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if unicode.Is(unicode.Thai, r) {
+			thai++
+		}
+	}
+	if letters > 0 && thai*3 >= letters {
+		return "th"
+	}
+	return "en"
+}
+
+// detectDiscType tags a chunk that talks about exactly one DISC style, by
+// its English or Thai name.
+func detectDiscType(text string) string {
+	lower := strings.ToLower(text)
+	found := ""
+	for _, letter := range []string{"D", "I", "S", "C"} {
+		style := models.DiscStyles[letter]
+		thai := strings.Fields(style.ThaiName)
+		if strings.Contains(lower, strings.ToLower(style.Name)) || (len(thai) > 0 && strings.Contains(text, thai[0])) {
+			if found != "" {
+				return ""
+			}
+			found = letter
+		}
+	}
+	return found
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
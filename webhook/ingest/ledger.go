@@ -0,0 +1,140 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// SourceRecord remembers what was last ingested for a source, so unchanged
+// sources are skipped and changed ones replace their old chunks.
+type SourceRecord struct {
+	ID          string   `bson:"_id" json:"id"`
+	Hash        string   `bson:"hash" json:"hash"`
+	ChunkHashes []string `bson:"chunkHashes" json:"chunkHashes"`
+	// SharedHashes are chunks left out because another source had them.
+	SharedHashes []string  `bson:"sharedHashes,omitempty" json:"sharedHashes,omitempty"`
+	IngestedAt   time.Time `bson:"ingestedAt" json:"ingestedAt"`
+}
+
+// Ledger stores one SourceRecord per ingested source.
+type Ledger interface {
+	All(ctx context.Context) ([]SourceRecord, error)
+	Put(ctx context.Context, rec SourceRecord) error
+	Delete(ctx context.Context, id string) error
+}
+
+type MongoLedger struct {
+	coll *mongo.Collection
+}
+
+func NewMongoLedger(coll *mongo.Collection) *MongoLedger {
+	return &MongoLedger{coll: coll}
+}
+
+func (l *MongoLedger) All(ctx context.Context) ([]SourceRecord, error) {
+	cursor, err := l.coll.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var records []SourceRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+func (l *MongoLedger) Put(ctx context.Context, rec SourceRecord) error {
+	_, err := l.coll.ReplaceOne(ctx, bson.M{"_id": rec.ID}, rec, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (l *MongoLedger) Delete(ctx context.Context, id string) error {
+	_, err := l.coll.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
+
+// FileLedger keeps records in a JSON file next to a saved memory store.
+// Put and Delete only change the records in memory; Save writes them, so
+// the caller can save the ledger right after the store it describes and
+// never list chunks the saved store lacks. With an empty path it only
+// lives in memory.
+type FileLedger struct {
+	mu      sync.Mutex
+	path    string
+	records map[string]SourceRecord
+}
+
+func LoadFileLedger(path string) (*FileLedger, error) {
+	l := &FileLedger{path: path, records: map[string]SourceRecord{}}
+	if path == "" {
+		return l, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []SourceRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, err
+	}
+	for _, rec := range records {
+		l.records[rec.ID] = rec
+	}
+	return l, nil
+}
+
+func (l *FileLedger) All(context.Context) ([]SourceRecord, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	records := make([]SourceRecord, 0, len(l.records))
+	for _, rec := range l.records {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	return records, nil
+}
+
+func (l *FileLedger) Put(_ context.Context, rec SourceRecord) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.records[rec.ID] = rec
+	return nil
+}
+
+func (l *FileLedger) Delete(_ context.Context, id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.records, id)
+	return nil
+}
+
+// Save writes the records to the ledger file.
+func (l *FileLedger) Save() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.path == "" {
+		return nil
+	}
+	records := make([]SourceRecord, 0, len(l.records))
+	for _, rec := range l.records {
+		records = append(records, rec)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].ID < records[j].ID })
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(l.path, data, 0o644)
+}
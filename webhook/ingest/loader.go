@@ -0,0 +1,220 @@
+package ingest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/tmc/langchaingo/documentloaders"
+)
+
+// section is a run of text under one heading (or one PDF page).
+type section struct {
+	Title string
+	Text  string
+}
+
+// maxSourceSize caps downloads and files so one bad source cannot exhaust
+// memory.
+const maxSourceSize = 20 << 20
+
+// fetch reads a source's raw bytes and settles its type.
+func (p *Pipeline) fetch(ctx context.Context, m *Manifest, s Source) ([]byte, string, error) {
+	if s.Path != "" {
+		file := m.resolve(s.Path)
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, "", err
+		}
+		defer f.Close()
+		data, err := io.ReadAll(io.LimitReader(f, maxSourceSize+1))
+		if err != nil {
+			return nil, "", err
+		}
+		if len(data) > maxSourceSize {
+			return nil, "", fmt.Errorf("%s is larger than %d bytes", file, maxSourceSize)
+		}
+		return data, detectType(s.Type, filepath.Ext(file), ""), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := p.httpClient().Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("GET %s: status %d", s.URL, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSourceSize+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxSourceSize {
+		return nil, "", fmt.Errorf("%s is larger than %d bytes", s.URL, maxSourceSize)
+	}
+	urlPath := s.URL
+	if req.URL != nil {
+		urlPath = req.URL.Path
+	}
+	return data, detectType(s.Type, path.Ext(urlPath), resp.Header.Get("Content-Type")), nil
+}
+
+func detectType(declared, ext, contentType string) string {
+	if declared != "" {
+		return declared
+	}
+	switch strings.ToLower(ext) {
+	case ".html", ".htm":
+		return TypeHTML
+	case ".md", ".markdown":
+		return TypeMarkdown
+	case ".pdf":
+		return TypePDF
+	case ".txt":
+		return TypeText
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		switch mediaType {
+		case "application/pdf":
+			return TypePDF
+		case "text/markdown":
+			return TypeMarkdown
+		case "text/plain":
+			return TypeText
+		}
+	}
+	// Web pages rarely have an extension.
+	return TypeHTML
+}
+
+// extract turns raw bytes into sections of plain text.
+func extract(ctx context.Context, kind string, data []byte) ([]section, error) {
+	switch kind {
+	case TypeHTML:
+		return htmlSections(data)
+	case TypeMarkdown:
+		return markdownSections(string(data)), nil
+	case TypePDF:
+		return pdfSections(ctx, data)
+	default:
+		return []section{{Text: string(data)}}, nil
+	}
+}
+
+// htmlSections groups the page's text blocks under their nearest heading,
+// leaving out navigation, scripts and other page chrome.
+func htmlSections(data []byte) ([]section, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	doc.Find("script, style, noscript, nav, header, footer, form, svg").Remove()
+
+	const blocks = "p, li, blockquote, pre, td, th"
+	var sections []section
+	current := section{Title: strings.TrimSpace(doc.Find("title").First().Text())}
+	var text strings.Builder
+
+	flush := func() {
+		if body := strings.TrimSpace(text.String()); body != "" {
+			current.Text = body
+			sections = append(sections, current)
+		}
+		text.Reset()
+	}
+
+	doc.Find("h1, h2, h3, h4, " + blocks).Each(func(_ int, s *goquery.Selection) {
+		if s.ParentsFiltered(blocks).Length() > 0 {
+			return // already part of an enclosing block
+		}
+		content := strings.Join(strings.Fields(s.Text()), " ")
+		if content == "" {
+			return
+		}
+		switch goquery.NodeName(s) {
+		case "h1", "h2", "h3", "h4":
+			flush()
+			current = section{Title: content}
+			return
+		}
+		text.WriteString(content)
+		text.WriteString("\n\n")
+	})
+	flush()
+
+	if len(sections) == 0 {
+		// No block markup: fall back to the whole body text.
+		body := strings.Join(strings.Fields(doc.Find("body").Text()), " ")
+		if body != "" {
+			sections = append(sections, section{Title: current.Title, Text: body})
+		}
+	}
+	return sections, nil
+}
+
+// markdownSections splits a Markdown document at its headings, ignoring
+// lines that only look like headings inside code fences.
+func markdownSections(doc string) []section {
+	var sections []section
+	var current section
+	var text strings.Builder
+	inFence := false
+
+	flush := func() {
+		if body := strings.TrimSpace(text.String()); body != "" {
+			current.Text = body
+			sections = append(sections, current)
+		}
+		text.Reset()
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(doc))
+	scanner.Buffer(make([]byte, 64<<10), maxSourceSize)
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") || strings.HasPrefix(trimmed, "~~~") {
+			inFence = !inFence
+		}
+		if !inFence && strings.HasPrefix(trimmed, "#") {
+			title := strings.TrimLeft(trimmed, "#")
+			if title == "" || title[0] == ' ' {
+				flush()
+				current = section{Title: strings.TrimSpace(title)}
+				continue
+			}
+		}
+		text.WriteString(line)
+		text.WriteString("\n")
+	}
+	flush()
+	return sections
+}
+
+func pdfSections(ctx context.Context, data []byte) ([]section, error) {
+	pages, err := documentloaders.NewPDF(bytes.NewReader(data), int64(len(data))).Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sections := make([]section, 0, len(pages))
+	for i, page := range pages {
+		if strings.TrimSpace(page.PageContent) == "" {
+			continue
+		}
+		sections = append(sections, section{Title: fmt.Sprintf("page %d", i+1), Text: page.PageContent})
+	}
+	return sections, nil
+}
@@ -0,0 +1,153 @@
+package ingest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Source types the pipeline can read.
+const (
+	TypeHTML     = "html"
+	TypeMarkdown = "markdown"
+	TypePDF      = "pdf"
+	TypeText     = "text"
+)
+
+// Manifest lists the documents that make up the knowledge base, e.g.
+//
+//	{
+//	  "defaults": {"chunkSize": 400, "chunkOverlap": 20},
+//	  "sources": [
+//	    {"id": "what-is-disc", "url": "https://example.com/disc", "language": "th"},
+//	    {"path": "styles/dominance.md", "discType": "D", "chunkSize": 600}
+//	  ]
+//	}
+//
+// Relative paths are resolved against the manifest's directory.
+type Manifest struct {
+	Defaults Chunking `json:"defaults"`
+	Sources  []Source `json:"sources"`
+
+	dir string
+}
+
+// Chunking controls how a source is split. ChunkOverlap is a pointer so an
+// explicit 0 turns overlap off instead of falling back to the default.
+type Chunking struct {
+	ChunkSize    int  `json:"chunkSize,omitempty"`
+	ChunkOverlap *int `json:"chunkOverlap,omitempty"`
+}
+
+// overlap is the chunk overlap, 0 when unset.
+func (c Chunking) overlap() int {
+	if c.ChunkOverlap == nil {
+		return 0
+	}
+	return *c.ChunkOverlap
+}
+
+type Source struct {
+	// ID names the source in chunk metadata; defaults to the path or URL.
+	ID   string `json:"id,omitempty"`
+	Path string `json:"path,omitempty"`
+	URL  string `json:"url,omitempty"`
+	// Type is html, markdown, pdf or text; guessed from the extension or
+	// Content-Type when empty.
+	Type string `json:"type,omitempty"`
+	// Language tags every chunk; detected per chunk when empty.
+	Language string `json:"language,omitempty"`
+	// DiscType tags every chunk with a DISC letter; detected per chunk
+	// when empty.
+	DiscType string `json:"discType,omitempty"`
+	Chunking
+}
+
+const (
+	defaultChunkSize    = 400
+	defaultChunkOverlap = 20
+)
+
+// LoadManifest reads and validates a manifest file.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	m.dir = filepath.Dir(path)
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Validate fills in source IDs and checks every source can be read.
+func (m *Manifest) Validate() error {
+	seen := map[string]bool{}
+	for i := range m.Sources {
+		s := &m.Sources[i]
+		if (s.Path == "") == (s.URL == "") {
+			return fmt.Errorf("source %d: set exactly one of path or url", i+1)
+		}
+		if s.ID == "" {
+			s.ID = s.Path + s.URL
+		}
+		if seen[s.ID] {
+			return fmt.Errorf("source %d: duplicate id %q", i+1, s.ID)
+		}
+		seen[s.ID] = true
+
+		switch s.Type {
+		case "", TypeHTML, TypeMarkdown, TypePDF, TypeText:
+		default:
+			return fmt.Errorf("source %q: unknown type %q", s.ID, s.Type)
+		}
+		s.DiscType = strings.ToUpper(s.DiscType)
+		switch s.DiscType {
+		case "", "D", "I", "S", "C":
+		default:
+			return fmt.Errorf("source %q: discType must be one of D, I, S, C", s.ID)
+		}
+
+		chunking := s.chunking(m.Defaults)
+		if chunking.ChunkSize < 0 || chunking.overlap() < 0 {
+			return fmt.Errorf("source %q: chunkSize and chunkOverlap must not be negative", s.ID)
+		}
+		if chunking.overlap() >= chunking.ChunkSize {
+			return fmt.Errorf("source %q: chunkOverlap must be smaller than chunkSize", s.ID)
+		}
+	}
+	return nil
+}
+
+// chunking applies the manifest defaults, then the built-in ones.
+func (s Source) chunking(defaults Chunking) Chunking {
+	c := s.Chunking
+	if c.ChunkSize == 0 {
+		c.ChunkSize = defaults.ChunkSize
+	}
+	if c.ChunkOverlap == nil {
+		c.ChunkOverlap = defaults.ChunkOverlap
+	}
+	if c.ChunkSize == 0 {
+		c.ChunkSize = defaultChunkSize
+	}
+	if c.ChunkOverlap == nil {
+		overlap := defaultChunkOverlap
+		c.ChunkOverlap = &overlap
+	}
+	return c
+}
+
+func (m *Manifest) resolve(path string) string {
+	if filepath.IsAbs(path) || m.dir == "" {
+		return path
+	}
+	return filepath.Join(m.dir, path)
+}
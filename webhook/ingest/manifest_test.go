@@ -0,0 +1,74 @@
+package ingest
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestChunking(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		manifest string
+		size     int
+		overlap  int
+		wantErr  bool
+	}{
+		{
+			name:     "built-in defaults",
+			manifest: `{"sources": [{"path": "a.md"}]}`,
+			size:     400,
+			overlap:  20,
+		},
+		{
+			name:     "manifest defaults",
+			manifest: `{"defaults": {"chunkSize": 600, "chunkOverlap": 50}, "sources": [{"path": "a.md"}]}`,
+			size:     600,
+			overlap:  50,
+		},
+		{
+			name:     "source overrides",
+			manifest: `{"defaults": {"chunkSize": 600, "chunkOverlap": 50}, "sources": [{"path": "a.md", "chunkSize": 300, "chunkOverlap": 10}]}`,
+			size:     300,
+			overlap:  10,
+		},
+		{
+			name:     "zero overlap in defaults",
+			manifest: `{"defaults": {"chunkOverlap": 0}, "sources": [{"path": "a.md"}]}`,
+			size:     400,
+			overlap:  0,
+		},
+		{
+			name:     "zero overlap on a source",
+			manifest: `{"defaults": {"chunkOverlap": 50}, "sources": [{"path": "a.md", "chunkOverlap": 0}]}`,
+			size:     400,
+			overlap:  0,
+		},
+		{
+			name:     "overlap as large as the chunk",
+			manifest: `{"sources": [{"path": "a.md", "chunkSize": 20}]}`,
+			wantErr:  true,
+		},
+		{
+			name:     "negative overlap",
+			manifest: `{"sources": [{"path": "a.md", "chunkOverlap": -1}]}`,
+			wantErr:  true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var m Manifest
+			if err := json.Unmarshal([]byte(tt.manifest), &m); err != nil {
+				t.Fatal(err)
+			}
+			if err := m.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate = %v, wantErr %t", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			got := m.Sources[0].chunking(m.Defaults)
+			if got.ChunkSize != tt.size || got.overlap() != tt.overlap {
+				t.Errorf("chunking = %d/%d, want %d/%d", got.ChunkSize, got.overlap(), tt.size, tt.overlap)
+			}
+		})
+	}
+}
@@ -0,0 +1,224 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"time"
+
+	"line-chatbot-golang-langchain/vectorstore"
+)
+
+// Outcomes of ingesting one source.
+const (
+	StatusAdded     = "added"
+	StatusUpdated   = "updated"
+	StatusUnchanged = "unchanged"
+	StatusRemoved   = "removed"
+	StatusFailed    = "failed"
+)
+
+type SourceReport struct {
+	ID      string
+	Status  string
+	Chunks  int   // chunks stored for the source now
+	Skipped int   // duplicate chunks left out
+	Deleted int64 // old chunks removed
+	Err     error
+}
+
+type Report struct {
+	Sources []SourceReport
+}
+
+// Failed counts the sources that could not be ingested.
+func (r *Report) Failed() int {
+	n := 0
+	for _, s := range r.Sources {
+		if s.Status == StatusFailed {
+			n++
+		}
+	}
+	return n
+}
+
+// Pipeline ingests a manifest into a vector store. A source is re-read on
+// every run but only re-embedded when its content hash changed; its old
+// chunks are then deleted before the new ones are added, so running the
+// pipeline twice never duplicates anything.
+type Pipeline struct {
+	Store  vectorstore.Store
+	Ledger Ledger
+	// Force re-ingests sources even when they did not change.
+	Force bool
+	// Prune removes sources that are no longer in the manifest.
+	Prune bool
+	// OnSource is called after each source, e.g. to print progress.
+	OnSource   func(SourceReport)
+	HTTPClient *http.Client
+}
+
+func (p *Pipeline) httpClient() *http.Client {
+	if p.HTTPClient != nil {
+		return p.HTTPClient
+	}
+	return &http.Client{Timeout: 60 * time.Second}
+}
+
+// Run ingests every source of the manifest. A failing source is reported
+// and skipped; the error is only for problems affecting the whole run.
+func (p *Pipeline) Run(ctx context.Context, m *Manifest) (*Report, error) {
+	records, err := p.Ledger.All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("read ingestion ledger: %w", err)
+	}
+	known := make(map[string]SourceRecord, len(records))
+	for _, rec := range records {
+		known[rec.ID] = rec
+	}
+
+	report := &Report{}
+	inManifest := map[string]bool{}
+	for _, s := range m.Sources {
+		inManifest[s.ID] = true
+	}
+
+	if p.Prune {
+		for _, rec := range records {
+			if inManifest[rec.ID] {
+				continue
+			}
+			sr := SourceReport{ID: rec.ID, Status: StatusRemoved}
+			sr.Deleted, sr.Err = p.Store.DeleteBySource(ctx, rec.ID)
+			if sr.Err == nil {
+				sr.Err = p.Ledger.Delete(ctx, rec.ID)
+			}
+			if sr.Err != nil {
+				sr.Status = StatusFailed
+			} else {
+				delete(known, rec.ID)
+			}
+			p.record(report, sr)
+		}
+	}
+
+	for _, s := range m.Sources {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		p.record(report, p.ingestSource(ctx, m, s, known))
+	}
+	return report, nil
+}
+
+func (p *Pipeline) record(report *Report, sr SourceReport) {
+	report.Sources = append(report.Sources, sr)
+	if sr.Err != nil {
+		log.Printf("❌ Ingest %s failed: %v", sr.ID, sr.Err)
+	}
+	if p.OnSource != nil {
+		p.OnSource(sr)
+	}
+}
+
+// ingestSource updates known so later sources dedupe against this one.
+func (p *Pipeline) ingestSource(ctx context.Context, m *Manifest, s Source, known map[string]SourceRecord) SourceReport {
+	sr := SourceReport{ID: s.ID}
+	fail := func(err error) SourceReport {
+		sr.Status = StatusFailed
+		sr.Err = err
+		return sr
+	}
+
+	data, kind, err := p.fetch(ctx, m, s)
+	if err != nil {
+		return fail(err)
+	}
+	chunking := s.chunking(m.Defaults)
+	// Chunking settings are part of the hash: changing them re-chunks.
+	sourceHash := Hash(fmt.Sprintf("%s\x00%s\x00%d/%d\x00%s\x00%s", kind, data, chunking.ChunkSize, chunking.overlap(), s.Language, s.DiscType))
+
+	// Chunks of other sources, which this one must not store again.
+	owned := map[string]bool{}
+	for id, rec := range known {
+		if id == s.ID {
+			continue
+		}
+		for _, h := range rec.ChunkHashes {
+			owned[h] = true
+		}
+	}
+
+	previous, seenBefore := known[s.ID]
+	if seenBefore && previous.Hash == sourceHash && !p.Force && allOwned(previous.SharedHashes, owned) {
+		sr.Status = StatusUnchanged
+		sr.Chunks = len(previous.ChunkHashes)
+		return sr
+	}
+
+	sections, err := extract(ctx, kind, data)
+	if err != nil {
+		return fail(fmt.Errorf("extract %s: %w", kind, err))
+	}
+	docs, err := chunk(s, chunking, sections)
+	if err != nil {
+		return fail(fmt.Errorf("chunk: %w", err))
+	}
+
+	// Drop chunks already stored for another source or repeated here.
+	kept := docs[:0]
+	hashes := make([]string, 0, len(docs))
+	var shared []string
+	for _, doc := range docs {
+		h := doc.Metadata[MetadataHash].(string)
+		if owned[h] {
+			if !slices.Contains(hashes, h) {
+				shared = append(shared, h)
+			}
+			sr.Skipped++
+			continue
+		}
+		owned[h] = true
+		hashes = append(hashes, h)
+		kept = append(kept, doc)
+	}
+
+	// Replace, never append: the source's old chunks go first.
+	sr.Deleted, err = p.Store.DeleteBySource(ctx, s.ID)
+	if err != nil {
+		return fail(fmt.Errorf("delete old chunks: %w", err))
+	}
+	if _, err := p.Store.Add(ctx, kept); err != nil {
+		// The old chunks are gone; forget the record so the next run
+		// re-ingests the source instead of reporting it unchanged.
+		_ = p.Ledger.Delete(ctx, s.ID)
+		delete(known, s.ID)
+		return fail(fmt.Errorf("store chunks: %w", err))
+	}
+
+	rec := SourceRecord{ID: s.ID, Hash: sourceHash, ChunkHashes: hashes, SharedHashes: shared, IngestedAt: time.Now()}
+	if err := p.Ledger.Put(ctx, rec); err != nil {
+		return fail(fmt.Errorf("update ledger: %w", err))
+	}
+	known[s.ID] = rec
+
+	sr.Status = StatusAdded
+	if seenBefore {
+		sr.Status = StatusUpdated
+	}
+	sr.Chunks = len(kept)
+	return sr
+}
+
+// allOwned reports whether every chunk a source shares is still stored by
+// some other source; if not, the source has to store it itself again.
+func allOwned(hashes []string, owned map[string]bool) bool {
+	for _, h := range hashes {
+		if !owned[h] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,145 @@
+package linebot
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const DefaultBaseURL = "https://api.line.me"
+
+// Client calls the LINE Messaging API with a channel access token. It is
+// safe for concurrent use and should be created once and shared.
+type Client struct {
+	baseURL     string
+	accessToken string
+	httpClient  *http.Client
+}
+
+type Option func(*Client)
+
+// WithBaseURL points the client at another host, e.g. a local fake in tests.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+func New(accessToken string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:     DefaultBaseURL,
+		accessToken: accessToken,
+		httpClient:  &http.Client{Timeout: 15 * time.Second},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is returned for any non-2xx response from LINE.
+type APIError struct {
+	StatusCode int
+	RequestID  string
+	// AcceptedRequestID is set on 409 responses to a retried push: the
+	// request with the same retry key that was already accepted.
+	AcceptedRequestID string
+	Message           string
+	Details           []ErrorDetail
+}
+
+type ErrorDetail struct {
+	Message  string `json:"message"`
+	Property string `json:"property"`
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("line API error: status %d", e.StatusCode)
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	for _, d := range e.Details {
+		msg += fmt.Sprintf(" [%s: %s]", d.Property, d.Message)
+	}
+	if e.RequestID != "" {
+		msg += " (request id " + e.RequestID + ")"
+	}
+	return msg
+}
+
+// do sends a request and decodes a JSON response into out when it is not
+// nil. It returns the X-Line-Request-Id of the response.
+func (c *Client) do(ctx context.Context, method, path string, headers map[string]string, in, out interface{}) (string, error) {
+	var body io.Reader
+	if in != nil {
+		payload, err := json.Marshal(in)
+		if err != nil {
+			return "", err
+		}
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	requestID := resp.Header.Get("X-Line-Request-Id")
+	if resp.StatusCode >= 300 {
+		apiErr := &APIError{
+			StatusCode:        resp.StatusCode,
+			RequestID:         requestID,
+			AcceptedRequestID: resp.Header.Get("X-Line-Accepted-Request-Id"),
+		}
+		var errBody struct {
+			Message string        `json:"message"`
+			Details []ErrorDetail `json:"details"`
+		}
+		raw, _ := io.ReadAll(resp.Body)
+		if json.Unmarshal(raw, &errBody) == nil {
+			apiErr.Message = errBody.Message
+			apiErr.Details = errBody.Details
+		} else {
+			apiErr.Message = strings.TrimSpace(string(raw))
+		}
+		return requestID, apiErr
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return requestID, fmt.Errorf("decode LINE response: %w", err)
+		}
+	}
+	return requestID, nil
+}
+
+func retryKeyHeader(retryKey string) map[string]string {
+	if retryKey == "" {
+		return nil
+	}
+	return map[string]string{"X-Line-Retry-Key": retryKey}
+}
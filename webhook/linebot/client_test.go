@@ -0,0 +1,166 @@
+package linebot
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+// fakeLine records the requests it receives and answers each with the next
+// handler in line, repeating the last one.
+type fakeLine struct {
+	mu        sync.Mutex
+	requests  []*http.Request
+	bodies    []map[string]interface{}
+	responses []http.HandlerFunc
+}
+
+func (f *fakeLine) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	f.mu.Lock()
+	n := len(f.requests)
+	f.requests = append(f.requests, r)
+	f.bodies = append(f.bodies, body)
+	respond := f.responses[min(n, len(f.responses)-1)]
+	f.mu.Unlock()
+
+	respond(w, r)
+}
+
+func newFakeLine(t *testing.T, responses ...http.HandlerFunc) (*fakeLine, *Client) {
+	t.Helper()
+	fake := &fakeLine{responses: responses}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, New("token", WithBaseURL(server.URL+"/"))
+}
+
+func respond(status int, body string, headers ...string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		for i := 0; i+1 < len(headers); i += 2 {
+			w.Header().Set(headers[i], headers[i+1])
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}
+}
+
+func text(s string) map[string]interface{} {
+	return map[string]interface{}{"type": "text", "text": s}
+}
+
+func TestPushDuplicateRetryKey(t *testing.T) {
+	fake, client := newFakeLine(t, respond(http.StatusConflict,
+		`{"message":"The retry key is already accepted"}`,
+		"X-Line-Request-Id", "req-2",
+		"X-Line-Accepted-Request-Id", "req-1",
+	))
+
+	_, err := client.PushMessage(context.Background(), "U1", []interface{}{text("hi")}, "key-1")
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusConflict || apiErr.RequestID != "req-2" || apiErr.AcceptedRequestID != "req-1" {
+		t.Errorf("APIError = %+v", apiErr)
+	}
+	if apiErr.Message != "The retry key is already accepted" {
+		t.Errorf("Message = %q", apiErr.Message)
+	}
+
+	r := fake.requests[0]
+	if r.URL.Path != "/v2/bot/message/push" {
+		t.Errorf("path = %q", r.URL.Path)
+	}
+	if got := r.Header.Get("X-Line-Retry-Key"); got != "key-1" {
+		t.Errorf("X-Line-Retry-Key = %q, want key-1", got)
+	}
+	if got := r.Header.Get("Authorization"); got != "Bearer token" {
+		t.Errorf("Authorization = %q", got)
+	}
+}
+
+func TestPushWithoutRetryKey(t *testing.T) {
+	fake, client := newFakeLine(t, respond(http.StatusOK, `{"sentMessages":[{"id":"1"}]}`))
+
+	if _, err := client.PushMessage(context.Background(), "U1", []interface{}{text("hi")}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := fake.requests[0].Header["X-Line-Retry-Key"]; ok {
+		t.Error("X-Line-Retry-Key sent without a retry key")
+	}
+}
+
+func TestPushRetriesReuseRetryKey(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		responses []http.HandlerFunc
+		attempts  int
+		wantErr   bool
+	}{
+		{
+			name: "server error then success",
+			responses: []http.HandlerFunc{
+				respond(http.StatusInternalServerError, `{"message":"internal error"}`),
+				respond(http.StatusOK, `{"sentMessages":[{"id":"1"}]}`),
+			},
+			attempts: 2,
+		},
+		{
+			// The first attempt reached LINE but its response was lost.
+			name: "server error then duplicate",
+			responses: []http.HandlerFunc{
+				respond(http.StatusServiceUnavailable, `{"message":"unavailable"}`),
+				respond(http.StatusConflict, `{"message":"The retry key is already accepted"}`),
+			},
+			attempts: 2,
+		},
+		{
+			name: "quota exhausted is not retried",
+			responses: []http.HandlerFunc{
+				respond(http.StatusTooManyRequests, `{"message":"You have reached your monthly limit."}`),
+			},
+			attempts: 1,
+			wantErr:  true,
+		},
+		{
+			name: "client error is not retried",
+			responses: []http.HandlerFunc{
+				respond(http.StatusBadRequest, `{"message":"invalid message"}`),
+			},
+			attempts: 1,
+			wantErr:  true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, client := newFakeLine(t, tt.responses...)
+			session := client.NewSession("", "U1")
+
+			err := session.Send(context.Background(), text("hi"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %t", err, tt.wantErr)
+			}
+			if len(fake.requests) != tt.attempts {
+				t.Fatalf("%d requests, want %d", len(fake.requests), tt.attempts)
+			}
+
+			key := fake.requests[0].Header.Get("X-Line-Retry-Key")
+			if key == "" {
+				t.Fatal("push sent without X-Line-Retry-Key")
+			}
+			for i, r := range fake.requests {
+				if got := r.Header.Get("X-Line-Retry-Key"); got != key {
+					t.Errorf("attempt %d X-Line-Retry-Key = %q, want %q", i+1, got, key)
+				}
+			}
+		})
+	}
+}
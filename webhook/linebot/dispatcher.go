@@ -0,0 +1,131 @@
+package linebot
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxMessagesPerRequest is the number of messages LINE accepts in a single
+// reply or push.
+const MaxMessagesPerRequest = 5
+
+// Session sends the answers to one webhook event. The reply token can only
+// be used once and expires shortly after the event, so the first batch is
+// sent as a reply and everything after it, or everything when the token is
+// spent, is pushed to the chat the event came from.
+type Session struct {
+	client     *Client
+	replyToken string
+	to         string
+	maxRetries int
+
+	mu        sync.Mutex
+	tokenUsed bool
+}
+
+// NewSession prepares a session for an event. to is the user, group or
+// room ID used for push fallback; replyToken may be empty.
+func (c *Client) NewSession(replyToken, to string) *Session {
+	return &Session{
+		client:     c,
+		replyToken: replyToken,
+		to:         to,
+		maxRetries: 3,
+	}
+}
+
+// Send delivers messages in batches of MaxMessagesPerRequest.
+func (s *Session) Send(ctx context.Context, messages ...interface{}) error {
+	for start := 0; start < len(messages); start += MaxMessagesPerRequest {
+		end := min(start+MaxMessagesPerRequest, len(messages))
+		if err := s.sendBatch(ctx, messages[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Session) sendBatch(ctx context.Context, batch []interface{}) error {
+	if replyToken, ok := s.takeReplyToken(); ok {
+		_, err := s.client.ReplyMessage(ctx, replyToken, batch)
+		if err == nil {
+			return nil
+		}
+		if !IsInvalidReplyToken(err) {
+			return err
+		}
+		log.Println("⚠️ Reply token spent or expired, falling back to push:", err)
+	}
+
+	if s.to == "" {
+		return errors.New("reply token unusable and no push target")
+	}
+	return s.push(ctx, batch)
+}
+
+func (s *Session) takeReplyToken() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tokenUsed || s.replyToken == "" {
+		return "", false
+	}
+	s.tokenUsed = true
+	return s.replyToken, true
+}
+
+// push retries server errors and network failures with the same
+// X-Line-Retry-Key, so LINE delivers the batch at most once however many
+// attempts reach it. A 429 on push means the monthly message quota is
+// used up, which retrying cannot fix, so it fails straight away.
+func (s *Session) push(ctx context.Context, batch []interface{}) error {
+	retryKey := uuid.NewString()
+	backoff := 500 * time.Millisecond
+
+	var err error
+	for attempt := 0; attempt <= s.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		_, err = s.client.PushMessage(ctx, s.to, batch, retryKey)
+		if err == nil {
+			return nil
+		}
+
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			if apiErr.StatusCode == http.StatusConflict {
+				// Already accepted under this retry key by an earlier attempt.
+				return nil
+			}
+			if apiErr.StatusCode < 500 {
+				return err
+			}
+		}
+		log.Printf("⚠️ Push attempt %d failed, retrying: %v", attempt+1, err)
+	}
+	return err
+}
+
+// IsInvalidReplyToken reports whether LINE rejected a reply because its
+// token was already used or has expired.
+func IsInvalidReplyToken(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	return apiErr.StatusCode == http.StatusBadRequest &&
+		strings.Contains(strings.ToLower(apiErr.Message), "reply token")
+}
@@ -0,0 +1,127 @@
+package linebot
+
+import (
+	"context"
+	"net/http"
+	"testing"
+)
+
+const invalidReplyToken = `{"message":"Invalid reply token"}`
+
+func TestSessionFallsBackToPush(t *testing.T) {
+	fake, client := newFakeLine(t,
+		respond(http.StatusBadRequest, invalidReplyToken),
+		respond(http.StatusOK, `{"sentMessages":[{"id":"1"}]}`),
+	)
+	session := client.NewSession("reply-1", "G1")
+
+	if err := session.Send(context.Background(), text("hi")); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.requests) != 2 {
+		t.Fatalf("%d requests, want reply then push", len(fake.requests))
+	}
+	if got := fake.requests[0].URL.Path; got != "/v2/bot/message/reply" {
+		t.Errorf("first request to %s, want reply", got)
+	}
+	if got := fake.bodies[0]["replyToken"]; got != "reply-1" {
+		t.Errorf("replyToken = %v", got)
+	}
+	if got := fake.requests[1].URL.Path; got != "/v2/bot/message/push" {
+		t.Errorf("second request to %s, want push", got)
+	}
+	if got := fake.bodies[1]["to"]; got != "G1" {
+		t.Errorf("push to = %v, want G1", got)
+	}
+	if got := len(fake.bodies[1]["messages"].([]interface{})); got != 1 {
+		t.Errorf("push carried %d messages, want 1", got)
+	}
+}
+
+func TestSessionReplyErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		to       string
+		reply    http.HandlerFunc
+		requests int
+	}{
+		{
+			// Anything but a spent token means the reply itself was bad;
+			// pushing it would fail the same way.
+			name:     "other client error is not pushed",
+			to:       "G1",
+			reply:    respond(http.StatusBadRequest, `{"message":"The request body has 1 error(s)"}`),
+			requests: 1,
+		},
+		{
+			name:     "spent token without push target",
+			reply:    respond(http.StatusBadRequest, invalidReplyToken),
+			requests: 1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, client := newFakeLine(t, tt.reply)
+			session := client.NewSession("reply-1", tt.to)
+
+			if err := session.Send(context.Background(), text("hi")); err == nil {
+				t.Fatal("Send succeeded, want error")
+			}
+			if len(fake.requests) != tt.requests {
+				t.Errorf("%d requests, want %d", len(fake.requests), tt.requests)
+			}
+		})
+	}
+}
+
+func TestSessionSplitsBatches(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		messages int
+		paths    []string
+		sizes    []int
+	}{
+		{
+			name:     "five fit in the reply",
+			messages: 5,
+			paths:    []string{"/v2/bot/message/reply"},
+			sizes:    []int{5},
+		},
+		{
+			name:     "sixth is pushed",
+			messages: 6,
+			paths:    []string{"/v2/bot/message/reply", "/v2/bot/message/push"},
+			sizes:    []int{5, 1},
+		},
+		{
+			name:     "twelve in three batches",
+			messages: 12,
+			paths:    []string{"/v2/bot/message/reply", "/v2/bot/message/push", "/v2/bot/message/push"},
+			sizes:    []int{5, 5, 2},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fake, client := newFakeLine(t, respond(http.StatusOK, `{"sentMessages":[]}`))
+			session := client.NewSession("reply-1", "U1")
+
+			messages := make([]interface{}, tt.messages)
+			for i := range messages {
+				messages[i] = text("hi")
+			}
+			if err := session.Send(context.Background(), messages...); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(fake.requests) != len(tt.paths) {
+				t.Fatalf("%d requests, want %d", len(fake.requests), len(tt.paths))
+			}
+			for i, r := range fake.requests {
+				if r.URL.Path != tt.paths[i] {
+					t.Errorf("request %d to %s, want %s", i+1, r.URL.Path, tt.paths[i])
+				}
+				if got := len(fake.bodies[i]["messages"].([]interface{})); got != tt.sizes[i] {
+					t.Errorf("request %d carried %d messages, want %d", i+1, got, tt.sizes[i])
+				}
+			}
+		})
+	}
+}
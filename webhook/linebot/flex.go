@@ -0,0 +1,233 @@
+package linebot
+
+import "encoding/json"
+
+// FlexMessage wraps a bubble or carousel so it can be sent like any other
+// message. AltText is what notifications and old clients show.
+type FlexMessage struct {
+	AltText    string        `json:"altText"`
+	Contents   FlexContainer `json:"contents"`
+	QuickReply interface{}   `json:"quickReply,omitempty"`
+}
+
+func (m FlexMessage) MarshalJSON() ([]byte, error) {
+	type alias FlexMessage
+	return marshalWithType("flex", alias(m))
+}
+
+// FlexContainer is a Bubble or a Carousel.
+type FlexContainer interface {
+	flexContainer()
+}
+
+// FlexComponent is anything that can be placed in a Box.
+type FlexComponent interface {
+	flexComponent()
+}
+
+// Action is a URI, postback or message action attached to a button or a
+// tappable component.
+type Action interface {
+	action()
+}
+
+type Bubble struct {
+	Size   string        `json:"size,omitempty"`
+	Header *Box          `json:"header,omitempty"`
+	Hero   FlexComponent `json:"hero,omitempty"`
+	Body   *Box          `json:"body,omitempty"`
+	Footer *Box          `json:"footer,omitempty"`
+	Styles *BubbleStyles `json:"styles,omitempty"`
+}
+
+type BubbleStyles struct {
+	Header *BlockStyle `json:"header,omitempty"`
+	Hero   *BlockStyle `json:"hero,omitempty"`
+	Body   *BlockStyle `json:"body,omitempty"`
+	Footer *BlockStyle `json:"footer,omitempty"`
+}
+
+type BlockStyle struct {
+	BackgroundColor string `json:"backgroundColor,omitempty"`
+	Separator       bool   `json:"separator,omitempty"`
+	SeparatorColor  string `json:"separatorColor,omitempty"`
+}
+
+func (Bubble) flexContainer() {}
+
+func (b Bubble) MarshalJSON() ([]byte, error) {
+	type alias Bubble
+	return marshalWithType("bubble", alias(b))
+}
+
+// Carousel holds up to 12 bubbles shown side by side.
+type Carousel struct {
+	Contents []*Bubble `json:"contents"`
+}
+
+func (Carousel) flexContainer() {}
+
+func (c Carousel) MarshalJSON() ([]byte, error) {
+	type alias Carousel
+	return marshalWithType("carousel", alias(c))
+}
+
+type Box struct {
+	Layout          string          `json:"layout"`
+	Contents        []FlexComponent `json:"contents"`
+	Spacing         string          `json:"spacing,omitempty"`
+	Margin          string          `json:"margin,omitempty"`
+	PaddingAll      string          `json:"paddingAll,omitempty"`
+	BackgroundColor string          `json:"backgroundColor,omitempty"`
+	CornerRadius    string          `json:"cornerRadius,omitempty"`
+	Flex            *int            `json:"flex,omitempty"`
+	Action          Action          `json:"action,omitempty"`
+}
+
+// NewBox returns a box with the given layout: "vertical", "horizontal" or
+// "baseline".
+func NewBox(layout string, contents ...FlexComponent) *Box {
+	if contents == nil {
+		contents = []FlexComponent{}
+	}
+	return &Box{Layout: layout, Contents: contents}
+}
+
+func (*Box) flexComponent() {}
+
+func (b *Box) MarshalJSON() ([]byte, error) {
+	type alias Box
+	return marshalWithType("box", (*alias)(b))
+}
+
+type Text struct {
+	Text     string `json:"text"`
+	Size     string `json:"size,omitempty"`
+	Weight   string `json:"weight,omitempty"`
+	Color    string `json:"color,omitempty"`
+	Align    string `json:"align,omitempty"`
+	Margin   string `json:"margin,omitempty"`
+	Wrap     bool   `json:"wrap,omitempty"`
+	MaxLines int    `json:"maxLines,omitempty"`
+	Flex     *int   `json:"flex,omitempty"`
+	Action   Action `json:"action,omitempty"`
+}
+
+func NewText(text string) *Text {
+	return &Text{Text: text}
+}
+
+func (*Text) flexComponent() {}
+
+func (t *Text) MarshalJSON() ([]byte, error) {
+	type alias Text
+	return marshalWithType("text", (*alias)(t))
+}
+
+type Image struct {
+	URL         string `json:"url"`
+	Size        string `json:"size,omitempty"`
+	AspectRatio string `json:"aspectRatio,omitempty"`
+	AspectMode  string `json:"aspectMode,omitempty"`
+	Margin      string `json:"margin,omitempty"`
+	Action      Action `json:"action,omitempty"`
+}
+
+func NewImage(url string) *Image {
+	return &Image{URL: url}
+}
+
+func (*Image) flexComponent() {}
+
+func (i *Image) MarshalJSON() ([]byte, error) {
+	type alias Image
+	return marshalWithType("image", (*alias)(i))
+}
+
+type Button struct {
+	Action Action `json:"action"`
+	Style  string `json:"style,omitempty"`
+	Color  string `json:"color,omitempty"`
+	Height string `json:"height,omitempty"`
+	Margin string `json:"margin,omitempty"`
+}
+
+// NewButton returns a button; style is "primary", "secondary" or "link".
+func NewButton(action Action, style string) *Button {
+	return &Button{Action: action, Style: style}
+}
+
+func (*Button) flexComponent() {}
+
+func (b *Button) MarshalJSON() ([]byte, error) {
+	type alias Button
+	return marshalWithType("button", (*alias)(b))
+}
+
+type Separator struct {
+	Margin string `json:"margin,omitempty"`
+	Color  string `json:"color,omitempty"`
+}
+
+func NewSeparator() *Separator {
+	return &Separator{}
+}
+
+func (*Separator) flexComponent() {}
+
+func (s *Separator) MarshalJSON() ([]byte, error) {
+	type alias Separator
+	return marshalWithType("separator", (*alias)(s))
+}
+
+type URIAction struct {
+	Label string `json:"label,omitempty"`
+	URI   string `json:"uri"`
+}
+
+func (URIAction) action() {}
+
+func (a URIAction) MarshalJSON() ([]byte, error) {
+	type alias URIAction
+	return marshalWithType("uri", alias(a))
+}
+
+type PostbackAction struct {
+	Label       string `json:"label,omitempty"`
+	Data        string `json:"data"`
+	DisplayText string `json:"displayText,omitempty"`
+}
+
+func (PostbackAction) action() {}
+
+func (a PostbackAction) MarshalJSON() ([]byte, error) {
+	type alias PostbackAction
+	return marshalWithType("postback", alias(a))
+}
+
+type MessageAction struct {
+	Label string `json:"label,omitempty"`
+	Text  string `json:"text"`
+}
+
+func (MessageAction) action() {}
+
+func (a MessageAction) MarshalJSON() ([]byte, error) {
+	type alias MessageAction
+	return marshalWithType("message", alias(a))
+}
+
+// marshalWithType encodes v and adds the "type" discriminator LINE uses on
+// every message, container, component and action.
+func marshalWithType(typ string, v interface{}) ([]byte, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+	fields["type"], _ = json.Marshal(typ)
+	return json.Marshal(fields)
+}
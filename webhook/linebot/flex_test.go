@@ -0,0 +1,66 @@
+package linebot
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+func TestFlexMarshalJSON(t *testing.T) {
+	flex := 2
+	for _, tt := range []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{
+			name: "empty box keeps contents",
+			v:    NewBox("vertical"),
+			want: `{"contents":[],"layout":"vertical","type":"box"}`,
+		},
+		{
+			name: "nil action omitted",
+			v:    &Text{Text: "hi", Flex: &flex},
+			want: `{"flex":2,"text":"hi","type":"text"}`,
+		},
+		{
+			name: "actions",
+			v: NewBox("horizontal",
+				NewButton(URIAction{Label: "open", URI: "https://example.com"}, "link"),
+				NewButton(PostbackAction{Data: "action=result", DisplayText: "ผล"}, ""),
+				NewButton(MessageAction{Label: "help", Text: "help"}, ""),
+			),
+			want: `{"contents":[` +
+				`{"action":{"label":"open","type":"uri","uri":"https://example.com"},"style":"link","type":"button"},` +
+				`{"action":{"data":"action=result","displayText":"ผล","type":"postback"},"type":"button"},` +
+				`{"action":{"label":"help","text":"help","type":"message"},"type":"button"}` +
+				`],"layout":"horizontal","type":"box"}`,
+		},
+		{
+			name: "message with carousel",
+			v: FlexMessage{
+				AltText: "alt",
+				Contents: &Carousel{Contents: []*Bubble{{
+					Hero:   &Image{URL: "https://example.com/a.png", AspectMode: "cover"},
+					Body:   NewBox("vertical", NewText("a"), NewSeparator()),
+					Styles: &BubbleStyles{Body: &BlockStyle{Separator: true}},
+				}}},
+			},
+			want: `{"altText":"alt","contents":{"contents":[{` +
+				`"body":{"contents":[{"text":"a","type":"text"},{"type":"separator"}],"layout":"vertical","type":"box"},` +
+				`"hero":{"aspectMode":"cover","type":"image","url":"https://example.com/a.png"},` +
+				`"styles":{"body":{"separator":true}},"type":"bubble"` +
+				`}],"type":"carousel"},"type":"flex"}`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.v)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, []byte(tt.want)) {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+		})
+	}
+}
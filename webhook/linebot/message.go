@@ -0,0 +1,121 @@
+package linebot
+
+import (
+	"context"
+	"net/http"
+)
+
+// SentMessage identifies a message LINE accepted; the quote token lets a
+// later message quote it.
+type SentMessage struct {
+	ID         string `json:"id"`
+	QuoteToken string `json:"quoteToken,omitempty"`
+}
+
+type SendResponse struct {
+	SentMessages []SentMessage `json:"sentMessages"`
+}
+
+// ReplyMessage answers an event with its reply token. Messages are sent as
+// given, so any value that marshals to a LINE message object works.
+func (c *Client) ReplyMessage(ctx context.Context, replyToken string, messages []interface{}) (*SendResponse, error) {
+	in := map[string]interface{}{
+		"replyToken": replyToken,
+		"messages":   messages,
+	}
+	var out SendResponse
+	if _, err := c.do(ctx, http.MethodPost, "/v2/bot/message/reply", nil, in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PushMessage sends messages to a user, group or room ID. A non-empty
+// retryKey (a UUID) makes retries of the same push safe.
+func (c *Client) PushMessage(ctx context.Context, to string, messages []interface{}, retryKey string) (*SendResponse, error) {
+	in := map[string]interface{}{
+		"to":       to,
+		"messages": messages,
+	}
+	var out SendResponse
+	if _, err := c.do(ctx, http.MethodPost, "/v2/bot/message/push", retryKeyHeader(retryKey), in, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Multicast sends the same messages to up to 500 user IDs.
+func (c *Client) Multicast(ctx context.Context, to []string, messages []interface{}, retryKey string) error {
+	in := map[string]interface{}{
+		"to":       to,
+		"messages": messages,
+	}
+	_, err := c.do(ctx, http.MethodPost, "/v2/bot/message/multicast", retryKeyHeader(retryKey), in, nil)
+	return err
+}
+
+// Broadcast sends messages to every friend of the bot.
+func (c *Client) Broadcast(ctx context.Context, messages []interface{}, retryKey string) error {
+	in := map[string]interface{}{
+		"messages": messages,
+	}
+	_, err := c.do(ctx, http.MethodPost, "/v2/bot/message/broadcast", retryKeyHeader(retryKey), in, nil)
+	return err
+}
+
+// NarrowcastRequest targets friends by audience and demographic filters.
+// Recipient and Filter take the JSON objects documented by LINE.
+type NarrowcastRequest struct {
+	Messages             []interface{}    `json:"messages"`
+	Recipient            interface{}      `json:"recipient,omitempty"`
+	Filter               interface{}      `json:"filter,omitempty"`
+	Limit                *NarrowcastLimit `json:"limit,omitempty"`
+	NotificationDisabled bool             `json:"notificationDisabled,omitempty"`
+}
+
+type NarrowcastLimit struct {
+	Max                int  `json:"max,omitempty"`
+	UpToRemainingQuota bool `json:"upToRemainingQuota,omitempty"`
+}
+
+// Narrowcast starts an asynchronous send and returns its request ID, which
+// is used to poll the progress of the narrowcast.
+func (c *Client) Narrowcast(ctx context.Context, req NarrowcastRequest, retryKey string) (string, error) {
+	return c.do(ctx, http.MethodPost, "/v2/bot/message/narrowcast", retryKeyHeader(retryKey), req, nil)
+}
+
+// ShowLoadingAnimation shows the typing indicator in a 1:1 chat for the
+// given number of seconds (5 to 60, in steps of 5).
+func (c *Client) ShowLoadingAnimation(ctx context.Context, chatID string, seconds int) error {
+	in := map[string]interface{}{
+		"chatId":         chatID,
+		"loadingSeconds": seconds,
+	}
+	_, err := c.do(ctx, http.MethodPost, "/v2/bot/chat/loading/start", nil, in, nil)
+	return err
+}
+
+type MessageQuota struct {
+	Type  string `json:"type"`
+	Value int64  `json:"value"`
+}
+
+type QuotaConsumption struct {
+	TotalUsage int64 `json:"totalUsage"`
+}
+
+func (c *Client) GetMessageQuota(ctx context.Context) (*MessageQuota, error) {
+	var out MessageQuota
+	if _, err := c.do(ctx, http.MethodGet, "/v2/bot/message/quota", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetMessageQuotaConsumption(ctx context.Context) (*QuotaConsumption, error) {
+	var out QuotaConsumption
+	if _, err := c.do(ctx, http.MethodGet, "/v2/bot/message/quota/consumption", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
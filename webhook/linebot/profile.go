@@ -0,0 +1,75 @@
+package linebot
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+type Profile struct {
+	UserID        string `json:"userId"`
+	DisplayName   string `json:"displayName"`
+	PictureURL    string `json:"pictureUrl,omitempty"`
+	StatusMessage string `json:"statusMessage,omitempty"`
+	Language      string `json:"language,omitempty"`
+}
+
+type GroupSummary struct {
+	GroupID    string `json:"groupId"`
+	GroupName  string `json:"groupName"`
+	PictureURL string `json:"pictureUrl,omitempty"`
+}
+
+// MemberIDs is one page of group member IDs. Next is empty on the last page.
+type MemberIDs struct {
+	MemberIDs []string `json:"memberIds"`
+	Next      string   `json:"next,omitempty"`
+}
+
+func (c *Client) GetProfile(ctx context.Context, userID string) (*Profile, error) {
+	var out Profile
+	if _, err := c.do(ctx, http.MethodGet, "/v2/bot/profile/"+url.PathEscape(userID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetGroupSummary(ctx context.Context, groupID string) (*GroupSummary, error) {
+	var out GroupSummary
+	if _, err := c.do(ctx, http.MethodGet, "/v2/bot/group/"+url.PathEscape(groupID)+"/summary", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetGroupMemberProfile(ctx context.Context, groupID, userID string) (*Profile, error) {
+	path := "/v2/bot/group/" + url.PathEscape(groupID) + "/member/" + url.PathEscape(userID)
+	var out Profile
+	if _, err := c.do(ctx, http.MethodGet, path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) GetRoomMemberProfile(ctx context.Context, roomID, userID string) (*Profile, error) {
+	path := "/v2/bot/room/" + url.PathEscape(roomID) + "/member/" + url.PathEscape(userID)
+	var out Profile
+	if _, err := c.do(ctx, http.MethodGet, path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetGroupMemberIDs returns one page of member IDs; pass the previous
+// page's Next as start, or "" for the first page.
+func (c *Client) GetGroupMemberIDs(ctx context.Context, groupID, start string) (*MemberIDs, error) {
+	path := "/v2/bot/group/" + url.PathEscape(groupID) + "/members/ids"
+	if start != "" {
+		path += "?start=" + url.QueryEscape(start)
+	}
+	var out MemberIDs
+	if _, err := c.do(ctx, http.MethodGet, path, nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,264 @@
+package linebot
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// Limits LINE enforces on textV2 messages.
+const (
+	MaxTextV2Length        = 5000
+	MaxTextV2Substitutions = 100
+)
+
+// placeholderPattern matches {placeholder}s and the {{ and }} escapes, so
+// an escaped brace is never read as the start of a placeholder.
+var placeholderPattern = regexp.MustCompile(`\{\{|\}\}|\{([a-zA-Z0-9_]+)\}`)
+var substitutionKeyPattern = regexp.MustCompile(`^[a-zA-Z0-9_]{1,20}$`)
+
+// Substitution is what a {placeholder} in a textV2 message is replaced
+// with: a user mention, an @All mention or a LINE emoji.
+type Substitution interface {
+	substitution() map[string]interface{}
+}
+
+type UserMention struct {
+	UserID string
+}
+
+func (m UserMention) substitution() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "mention",
+		"mentionee": map[string]interface{}{
+			"type":   "user",
+			"userId": m.UserID,
+		},
+	}
+}
+
+type AllMention struct{}
+
+func (AllMention) substitution() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "mention",
+		"mentionee": map[string]interface{}{
+			"type": "all",
+		},
+	}
+}
+
+type Emoji struct {
+	ProductID string
+	EmojiID   string
+}
+
+func (e Emoji) substitution() map[string]interface{} {
+	return map[string]interface{}{
+		"type":      "emoji",
+		"productId": e.ProductID,
+		"emojiId":   e.EmojiID,
+	}
+}
+
+// EscapeTextV2 makes text safe to put in a textV2 template: LINE reads
+// {{ and }} as literal braces, so text from users or an LLM can never add
+// a placeholder.
+func EscapeTextV2(text string) string {
+	return strings.NewReplacer("{", "{{", "}", "}}").Replace(text)
+}
+
+// TextV2 is a text message whose {placeholders} are replaced by mentions
+// or emojis. Build it with NewTextV2 so the limits are checked.
+type TextV2 struct {
+	Text         string
+	Substitution map[string]Substitution
+	QuoteToken   string
+	QuickReply   interface{}
+}
+
+func (t TextV2) MarshalJSON() ([]byte, error) {
+	out := map[string]interface{}{
+		"type": "textV2",
+		"text": t.Text,
+	}
+	if len(t.Substitution) > 0 {
+		subs := make(map[string]interface{}, len(t.Substitution))
+		for key, sub := range t.Substitution {
+			subs[key] = sub.substitution()
+		}
+		out["substitution"] = subs
+	}
+	if t.QuoteToken != "" {
+		out["quoteToken"] = t.QuoteToken
+	}
+	if t.QuickReply != nil {
+		out["quickReply"] = t.QuickReply
+	}
+	return json.Marshal(out)
+}
+
+// NewTextV2 checks that every placeholder in text has a substitution, that
+// every substitution is used, and that LINE's limits are respected.
+func NewTextV2(text string, subs map[string]Substitution) (TextV2, error) {
+	if text == "" {
+		return TextV2{}, fmt.Errorf("textV2: empty text")
+	}
+	if n := utf8.RuneCountInString(text); n > MaxTextV2Length {
+		return TextV2{}, fmt.Errorf("textV2: text is %d characters, limit is %d", n, MaxTextV2Length)
+	}
+	if len(subs) > MaxTextV2Substitutions {
+		return TextV2{}, fmt.Errorf("textV2: %d substitutions, limit is %d", len(subs), MaxTextV2Substitutions)
+	}
+
+	used := map[string]bool{}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(text, -1) {
+		key := match[1]
+		if key == "" {
+			continue
+		}
+		if _, ok := subs[key]; !ok {
+			return TextV2{}, fmt.Errorf("textV2: placeholder {%s} has no substitution", key)
+		}
+		used[key] = true
+	}
+	for key := range subs {
+		if !substitutionKeyPattern.MatchString(key) {
+			return TextV2{}, fmt.Errorf("textV2: invalid substitution key %q", key)
+		}
+		if !used[key] {
+			return TextV2{}, fmt.Errorf("textV2: substitution %q is not used in the text", key)
+		}
+	}
+
+	return TextV2{Text: text, Substitution: subs}, nil
+}
+
+// WithoutMentions replaces mention placeholders with plain words, for 1:1
+// chats where LINE rejects mentions. Emoji substitutions are kept. A
+// mention right after the same word is dropped, so "สวัสดีคุณ {user1}"
+// reads "สวัสดีคุณ" rather than "สวัสดีคุณ คุณ".
+func (t TextV2) WithoutMentions(userWord, allWord string) TextV2 {
+	subs := map[string]Substitution{}
+	var text strings.Builder
+	last := 0
+	for _, loc := range placeholderPattern.FindAllStringSubmatchIndex(t.Text, -1) {
+		if loc[2] < 0 {
+			continue
+		}
+		prefix := t.Text[last:loc[0]]
+		placeholder := t.Text[loc[0]:loc[1]]
+		key := t.Text[loc[2]:loc[3]]
+		last = loc[1]
+
+		word := ""
+		switch sub := t.Substitution[key].(type) {
+		case UserMention:
+			word = userWord
+		case AllMention:
+			word = allWord
+		case nil:
+			text.WriteString(prefix + placeholder)
+			continue
+		default:
+			subs[key] = sub
+			text.WriteString(prefix + placeholder)
+			continue
+		}
+
+		if trimmed := strings.TrimRight(prefix, " "); word != "" && strings.HasSuffix(trimmed, word) {
+			text.WriteString(trimmed)
+			continue
+		}
+		text.WriteString(prefix + word)
+	}
+	text.WriteString(t.Text[last:])
+
+	t.Text = text.String()
+	t.Substitution = subs
+	return t
+}
+
+// TextV2Builder assembles a long textV2 reply line by line, such as a list
+// of every member of a large group, and starts a new message whenever the
+// next line would break the text or substitution limits.
+type TextV2Builder struct {
+	messages []TextV2
+	text     strings.Builder
+	subs     map[string]Substitution
+	next     int
+}
+
+func NewTextV2Builder() *TextV2Builder {
+	return &TextV2Builder{subs: map[string]Substitution{}}
+}
+
+// Line appends one line; an empty template adds a blank line. Placeholder keys only need to be unique within
+// the line; the builder renames them so lines never collide.
+func (b *TextV2Builder) Line(template string, subs map[string]Substitution) error {
+	if template == "" {
+		if b.text.Len() > 0 {
+			b.text.WriteString("\n")
+		}
+		return nil
+	}
+
+	line, err := NewTextV2(template, subs)
+	if err != nil {
+		return err
+	}
+
+	renamed := map[string]string{}
+	for key := range line.Substitution {
+		renamed[key] = ""
+	}
+	lineLen := utf8.RuneCountInString(line.Text) + 1
+
+	if b.text.Len() > 0 && (utf8.RuneCountInString(b.text.String())+lineLen > MaxTextV2Length ||
+		len(b.subs)+len(renamed) > MaxTextV2Substitutions) {
+		b.flush()
+	}
+
+	text := placeholderPattern.ReplaceAllStringFunc(line.Text, func(placeholder string) string {
+		key := placeholder[1 : len(placeholder)-1]
+		if _, ok := renamed[key]; !ok {
+			return placeholder
+		}
+		if renamed[key] == "" {
+			b.next++
+			newKey := fmt.Sprintf("s%d", b.next)
+			renamed[key] = newKey
+			b.subs[newKey] = line.Substitution[key]
+		}
+		return "{" + renamed[key] + "}"
+	})
+
+	if b.text.Len() > 0 {
+		b.text.WriteString("\n")
+	}
+	b.text.WriteString(text)
+	return nil
+}
+
+func (b *TextV2Builder) flush() {
+	if b.text.Len() == 0 {
+		return
+	}
+	b.messages = append(b.messages, TextV2{
+		Text:         strings.TrimRight(b.text.String(), "\n"),
+		Substitution: b.subs,
+	})
+	b.text.Reset()
+	b.subs = map[string]Substitution{}
+}
+
+// Messages returns the built messages. It can be called more than once and
+// returns a new slice each time, so changing a message, e.g. to add a quick
+// reply, does not change the builder. It is empty when no line has been
+// written.
+func (b *TextV2Builder) Messages() []TextV2 {
+	b.flush()
+	return append([]TextV2(nil), b.messages...)
+}
@@ -0,0 +1,112 @@
+package linebot
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestWithoutMentions(t *testing.T) {
+	smile := Emoji{ProductID: "p", EmojiID: "1"}
+	for _, tt := range []struct {
+		name string
+		text string
+		subs map[string]Substitution
+		want string
+	}{
+		{
+			name: "honorific before mention",
+			text: "สวัสดีคุณ {user1}! ยินดีต้อนรับ",
+			subs: map[string]Substitution{"user1": UserMention{UserID: "U1"}},
+			want: "สวัสดีคุณ! ยินดีต้อนรับ",
+		},
+		{
+			name: "mention at start",
+			text: "{user1} อยู่ในกลุ่ม D",
+			subs: map[string]Substitution{"user1": UserMention{UserID: "U1"}},
+			want: "คุณ อยู่ในกลุ่ม D",
+		},
+		{
+			name: "everyone after the same word",
+			text: "ทุกคน {everyone} มีเพื่อนใหม่",
+			subs: map[string]Substitution{"everyone": AllMention{}},
+			want: "ทุกคน มีเพื่อนใหม่",
+		},
+		{
+			name: "emoji kept",
+			text: "ถามได้เลย {user1} {smile}",
+			subs: map[string]Substitution{"user1": UserMention{UserID: "U1"}, "smile": smile},
+			want: "ถามได้เลย คุณ {smile}",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			message, err := NewTextV2(tt.text, tt.subs)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := message.WithoutMentions("คุณ", "ทุกคน")
+			if got.Text != tt.want {
+				t.Errorf("Text = %q, want %q", got.Text, tt.want)
+			}
+			for key, sub := range got.Substitution {
+				if _, ok := sub.(Emoji); !ok {
+					t.Errorf("substitution %q kept: %#v", key, sub)
+				}
+			}
+		})
+	}
+}
+
+func TestTextV2BuilderMessages(t *testing.T) {
+	builder := NewTextV2Builder()
+	if got := builder.Messages(); len(got) != 0 {
+		t.Fatalf("empty builder returned %d messages", len(got))
+	}
+
+	for i := 0; i < MaxTextV2Substitutions+1; i++ {
+		if err := builder.Line(fmt.Sprintf("- {user} %d", i), map[string]Substitution{"user": UserMention{UserID: "U"}}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first := builder.Messages()
+	if len(first) != 2 {
+		t.Fatalf("%d messages, want 2 once substitutions run out", len(first))
+	}
+	first[1].QuickReply = "changed"
+
+	second := builder.Messages()
+	if len(second) != 2 {
+		t.Fatalf("second call returned %d messages, want 2", len(second))
+	}
+	if second[1].QuickReply != nil {
+		t.Error("changing a returned message changed the builder")
+	}
+}
+
+func TestEscapedTextIsNotATemplate(t *testing.T) {
+	advice := "ตั้ง {user1} เป็นผู้นำ {everyone} }{ {{x}}"
+	template := "- {user} " + EscapeTextV2(advice)
+	subs := map[string]Substitution{"user": UserMention{UserID: "U1"}}
+
+	message, err := NewTextV2(template, subs)
+	if err != nil {
+		t.Fatalf("NewTextV2: %v", err)
+	}
+	if len(message.Substitution) != 1 {
+		t.Errorf("substitutions = %v, want only user", message.Substitution)
+	}
+
+	builder := NewTextV2Builder()
+	if err := builder.Line(template, subs); err != nil {
+		t.Fatal(err)
+	}
+	built := builder.Messages()
+	if want := "- {s1} " + EscapeTextV2(advice); built[0].Text != want {
+		t.Errorf("built %q, want %q", built[0].Text, want)
+	}
+
+	plain := message.WithoutMentions("คุณ", "ทุกคน")
+	if want := "- คุณ " + EscapeTextV2(advice); plain.Text != want {
+		t.Errorf("WithoutMentions = %q, want %q", plain.Text, want)
+	}
+}
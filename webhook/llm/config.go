@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"os"
+)
+
+// FromEnv builds the configured provider:
+//
+//	LLM_PROVIDER  gemini (default), openai or local
+//	LLM_MODEL     model name; defaults per provider
+//	LLM_BASE_URL  API root for openai/local
+//	LLM_API_KEY   key for openai/local; Gemini uses GEMINI_API_KEY
+func FromEnv(ctx context.Context) (LLM, error) {
+	provider := os.Getenv("LLM_PROVIDER")
+	model := os.Getenv("LLM_MODEL")
+
+	switch provider {
+	case "", "gemini":
+		if model == "" {
+			model = "gemini-2.0-flash"
+		}
+		return NewGemini(ctx, os.Getenv("GEMINI_API_KEY"), model)
+	case "openai":
+		baseURL := envOr("LLM_BASE_URL", "https://api.openai.com/v1")
+		if model == "" {
+			model = "gpt-4o-mini"
+		}
+		return NewOpenAICompatible(baseURL, os.Getenv("LLM_API_KEY"), model), nil
+	case "local":
+		baseURL := envOr("LLM_BASE_URL", "http://localhost:11434/v1")
+		if model == "" {
+			return nil, fmt.Errorf("LLM_MODEL is required for the local provider")
+		}
+		return NewOpenAICompatible(baseURL, os.Getenv("LLM_API_KEY"), model), nil
+	default:
+		return nil, fmt.Errorf("unknown LLM_PROVIDER %q", provider)
+	}
+}
+
+func envOr(key, def string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return def
+}
@@ -0,0 +1,73 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrScriptExhausted is returned once a Fake has no scripted replies left.
+var ErrScriptExhausted = errors.New("fake llm: no scripted replies left")
+
+// Fake replays scripted replies in order and records every prompt, for
+// tests that must not call a real model. A reply with Err set fails the
+// call instead.
+type Fake struct {
+	mu      sync.Mutex
+	replies []FakeReply
+	prompts []string
+	// Default is returned once the script runs out, if set.
+	Default *FakeReply
+}
+
+type FakeReply struct {
+	Text string
+	Err  error
+}
+
+func NewFake(replies ...FakeReply) *Fake {
+	return &Fake{replies: replies}
+}
+
+func (f *Fake) Model() string {
+	return "fake"
+}
+
+func (f *Fake) next(prompt string) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.prompts = append(f.prompts, prompt)
+
+	if len(f.replies) == 0 {
+		if f.Default != nil {
+			return f.Default.Text, f.Default.Err
+		}
+		return "", ErrScriptExhausted
+	}
+	reply := f.replies[0]
+	f.replies = f.replies[1:]
+	return reply.Text, reply.Err
+}
+
+func (f *Fake) Generate(_ context.Context, prompt string, _ ...Option) (string, error) {
+	return f.next(prompt)
+}
+
+func (f *Fake) GenerateStructured(_ context.Context, prompt string, _ *Schema, _ ...Option) (string, error) {
+	return f.next(prompt)
+}
+
+func (f *Fake) Stream(_ context.Context, prompt string, onChunk func(string) error, _ ...Option) error {
+	text, err := f.next(prompt)
+	if err != nil {
+		return err
+	}
+	return onChunk(text)
+}
+
+// Prompts returns every prompt received so far.
+func (f *Fake) Prompts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.prompts...)
+}
@@ -0,0 +1,149 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/generative-ai-go/genai"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+// Gemini keeps one genai client for the life of the process.
+type Gemini struct {
+	client *genai.Client
+	model  string
+}
+
+func NewGemini(ctx context.Context, apiKey, model string) (*Gemini, error) {
+	client, err := genai.NewClient(ctx, option.WithAPIKey(apiKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini client: %w", err)
+	}
+	return &Gemini{client: client, model: model}, nil
+}
+
+func (g *Gemini) Model() string {
+	return g.model
+}
+
+func (g *Gemini) Close() error {
+	return g.client.Close()
+}
+
+// generativeModel returns a fresh model handle per call: the handle holds
+// per-call settings, so sharing one between goroutines would race.
+func (g *Gemini) generativeModel(o Options) *genai.GenerativeModel {
+	model := g.client.GenerativeModel(g.model)
+	if o.System != "" {
+		model.SystemInstruction = &genai.Content{Parts: []genai.Part{genai.Text(o.System)}}
+	}
+	if o.Temperature != nil {
+		model.SetTemperature(*o.Temperature)
+	}
+	if o.MaxTokens > 0 {
+		model.SetMaxOutputTokens(int32(o.MaxTokens))
+	}
+	return model
+}
+
+func (g *Gemini) Generate(ctx context.Context, prompt string, opts ...Option) (string, error) {
+	model := g.generativeModel(buildOptions(opts))
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return "", fmt.Errorf("Gemini content generation failed: %w", err)
+	}
+	return responseText(resp)
+}
+
+func (g *Gemini) GenerateStructured(ctx context.Context, prompt string, schema *Schema, opts ...Option) (string, error) {
+	model := g.generativeModel(buildOptions(opts))
+	model.ResponseMIMEType = "application/json"
+	model.ResponseSchema = toGenaiSchema(schema)
+
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return "", fmt.Errorf("Gemini content generation failed: %w", err)
+	}
+	return responseText(resp)
+}
+
+func (g *Gemini) Stream(ctx context.Context, prompt string, onChunk func(string) error, opts ...Option) error {
+	model := g.generativeModel(buildOptions(opts))
+	iter := model.GenerateContentStream(ctx, genai.Text(prompt))
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("Gemini stream failed: %w", err)
+		}
+		text, err := responseText(resp)
+		if err == ErrEmptyResponse {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := onChunk(text); err != nil {
+			return err
+		}
+	}
+}
+
+func responseText(resp *genai.GenerateContentResponse) (string, error) {
+	if resp == nil || len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return "", ErrEmptyResponse
+	}
+	var sb strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if text, ok := part.(genai.Text); ok {
+			sb.WriteString(string(text))
+		}
+	}
+	if sb.Len() == 0 {
+		return "", ErrEmptyResponse
+	}
+	return sb.String(), nil
+}
+
+func toGenaiSchema(s *Schema) *genai.Schema {
+	if s == nil {
+		return nil
+	}
+	out := &genai.Schema{
+		Type:        genaiType(s.Type),
+		Description: s.Description,
+		Enum:        s.Enum,
+		Required:    s.Required,
+		Items:       toGenaiSchema(s.Items),
+	}
+	if len(s.Properties) > 0 {
+		out.Properties = make(map[string]*genai.Schema, len(s.Properties))
+		for name, prop := range s.Properties {
+			out.Properties[name] = toGenaiSchema(prop)
+		}
+	}
+	return out
+}
+
+func genaiType(t string) genai.Type {
+	switch t {
+	case "object":
+		return genai.TypeObject
+	case "array":
+		return genai.TypeArray
+	case "string":
+		return genai.TypeString
+	case "number":
+		return genai.TypeNumber
+	case "integer":
+		return genai.TypeInteger
+	case "boolean":
+		return genai.TypeBoolean
+	default:
+		return genai.TypeUnspecified
+	}
+}
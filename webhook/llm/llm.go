@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"errors"
+)
+
+// LLM is a text generation backend. Implementations are long-lived and
+// safe for concurrent use.
+type LLM interface {
+	// Generate returns the model's answer to a prompt.
+	Generate(ctx context.Context, prompt string, opts ...Option) (string, error)
+	// GenerateStructured asks for JSON matching schema and returns the raw
+	// JSON text. Backends that support it enforce the schema server-side.
+	GenerateStructured(ctx context.Context, prompt string, schema *Schema, opts ...Option) (string, error)
+	// Stream calls onChunk with each piece of the answer as it arrives.
+	Stream(ctx context.Context, prompt string, onChunk func(chunk string) error, opts ...Option) error
+	// Model names the model answering, for logging and stored results.
+	Model() string
+}
+
+var ErrEmptyResponse = errors.New("llm returned no content")
+
+// Options tune a single call.
+type Options struct {
+	System      string
+	Temperature *float32
+	MaxTokens   int
+}
+
+type Option func(*Options)
+
+func WithSystem(system string) Option {
+	return func(o *Options) { o.System = system }
+}
+
+func WithTemperature(t float32) Option {
+	return func(o *Options) { o.Temperature = &t }
+}
+
+func WithMaxTokens(n int) Option {
+	return func(o *Options) { o.MaxTokens = n }
+}
+
+func buildOptions(opts []Option) Options {
+	var o Options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Schema is the subset of JSON Schema both Gemini and OpenAI-compatible
+// servers understand.
+type Schema struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Enum        []string           `json:"enum,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	// AdditionalProperties must be false on objects for OpenAI strict mode;
+	// Gemini ignores it.
+	AdditionalProperties *bool `json:"additionalProperties,omitempty"`
+}
@@ -0,0 +1,169 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAICompatible talks to any server implementing the OpenAI chat
+// completions API, including local model servers such as Ollama, vLLM or
+// llama.cpp, so the bot can run without a cloud provider.
+type OpenAICompatible struct {
+	baseURL    string
+	apiKey     string
+	model      string
+	httpClient *http.Client
+}
+
+// NewOpenAICompatible takes the API root, e.g. "https://api.openai.com/v1"
+// or "http://localhost:11434/v1". apiKey may be empty for local servers.
+func NewOpenAICompatible(baseURL, apiKey, model string) *OpenAICompatible {
+	return &OpenAICompatible{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		apiKey:     apiKey,
+		model:      model,
+		httpClient: &http.Client{Timeout: 120 * time.Second},
+	}
+}
+
+func (o *OpenAICompatible) Model() string {
+	return o.model
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatRequest struct {
+	Model          string                 `json:"model"`
+	Messages       []chatMessage          `json:"messages"`
+	Temperature    *float32               `json:"temperature,omitempty"`
+	MaxTokens      int                    `json:"max_tokens,omitempty"`
+	Stream         bool                   `json:"stream,omitempty"`
+	ResponseFormat map[string]interface{} `json:"response_format,omitempty"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+		Delta   chatMessage `json:"delta"`
+	} `json:"choices"`
+}
+
+func (o *OpenAICompatible) newRequest(prompt string, opts []Option) chatRequest {
+	options := buildOptions(opts)
+	req := chatRequest{
+		Model:       o.model,
+		Temperature: options.Temperature,
+		MaxTokens:   options.MaxTokens,
+	}
+	if options.System != "" {
+		req.Messages = append(req.Messages, chatMessage{Role: "system", Content: options.System})
+	}
+	req.Messages = append(req.Messages, chatMessage{Role: "user", Content: prompt})
+	return req
+}
+
+func (o *OpenAICompatible) Generate(ctx context.Context, prompt string, opts ...Option) (string, error) {
+	return o.complete(ctx, o.newRequest(prompt, opts))
+}
+
+func (o *OpenAICompatible) GenerateStructured(ctx context.Context, prompt string, schema *Schema, opts ...Option) (string, error) {
+	req := o.newRequest(prompt, opts)
+	req.ResponseFormat = map[string]interface{}{
+		"type": "json_schema",
+		"json_schema": map[string]interface{}{
+			"name":   "response",
+			"schema": schema,
+			"strict": true,
+		},
+	}
+	return o.complete(ctx, req)
+}
+
+func (o *OpenAICompatible) complete(ctx context.Context, req chatRequest) (string, error) {
+	resp, err := o.post(ctx, req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var out chatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return "", fmt.Errorf("decode chat completion: %w", err)
+	}
+	if len(out.Choices) == 0 || out.Choices[0].Message.Content == "" {
+		return "", ErrEmptyResponse
+	}
+	return out.Choices[0].Message.Content, nil
+}
+
+// Stream reads the server-sent events of a streamed completion.
+func (o *OpenAICompatible) Stream(ctx context.Context, prompt string, onChunk func(string) error, opts ...Option) error {
+	req := o.newRequest(prompt, opts)
+	req.Stream = true
+
+	resp, err := o.post(ctx, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			return nil
+		}
+		var chunk chatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("decode stream chunk: %w", err)
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+		if err := onChunk(chunk.Choices[0].Delta.Content); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func (o *OpenAICompatible) post(ctx context.Context, body chatRequest) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, o.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if o.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+o.apiKey)
+	}
+
+	resp, err := o.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("chat completion failed: status %d: %s", resp.StatusCode, strings.TrimSpace(string(raw)))
+	}
+	return resp, nil
+}
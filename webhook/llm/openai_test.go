@@ -0,0 +1,246 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// fakeServer answers /chat/completions with handler and records the
+// decoded request bodies.
+func fakeServer(t *testing.T, handler http.HandlerFunc) (*OpenAICompatible, *[]map[string]interface{}) {
+	t.Helper()
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("request %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer key" {
+			t.Errorf("Authorization = %q", got)
+		}
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		bodies = append(bodies, body)
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return NewOpenAICompatible(server.URL+"/v1/", "key", "test-model"), &bodies
+}
+
+func completion(content string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"choices":[{"message":{"role":"assistant","content":%q}}]}`, content)
+	}
+}
+
+func TestOpenAIGenerateStructured(t *testing.T) {
+	o, bodies := fakeServer(t, completion(`{"type":"D"}`))
+	no := false
+	schema := &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{"type": {Type: "string", Enum: []string{"D", "I"}}},
+		Required:             []string{"type"},
+		AdditionalProperties: &no,
+	}
+
+	got, err := o.GenerateStructured(context.Background(), "classify", schema, WithSystem("be brief"), WithTemperature(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != `{"type":"D"}` {
+		t.Errorf("GenerateStructured = %q", got)
+	}
+
+	var want map[string]interface{}
+	if err := json.Unmarshal([]byte(`{
+		"model": "test-model",
+		"temperature": 0,
+		"messages": [
+			{"role": "system", "content": "be brief"},
+			{"role": "user", "content": "classify"}
+		],
+		"response_format": {
+			"type": "json_schema",
+			"json_schema": {
+				"name": "response",
+				"strict": true,
+				"schema": {
+					"type": "object",
+					"properties": {"type": {"type": "string", "enum": ["D", "I"]}},
+					"required": ["type"],
+					"additionalProperties": false
+				}
+			}
+		}
+	}`), &want); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual((*bodies)[0], want) {
+		got, _ := json.Marshal((*bodies)[0])
+		t.Errorf("request body = %s", got)
+	}
+}
+
+func TestOpenAIGenerate(t *testing.T) {
+	o, bodies := fakeServer(t, completion("สวัสดี"))
+	got, err := o.Generate(context.Background(), "hi", WithMaxTokens(10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "สวัสดี" {
+		t.Errorf("Generate = %q", got)
+	}
+	body := (*bodies)[0]
+	if _, ok := body["response_format"]; ok {
+		t.Error("plain Generate sent a response_format")
+	}
+	if _, ok := body["stream"]; ok {
+		t.Error("plain Generate asked for a stream")
+	}
+	if body["max_tokens"] != float64(10) {
+		t.Errorf("max_tokens = %v", body["max_tokens"])
+	}
+}
+
+func TestOpenAIStream(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		events  string
+		chunks  []string
+		wantErr bool
+	}{
+		{
+			name: "stops at DONE",
+			events: "data: {\"choices\":[{\"delta\":{\"role\":\"assistant\"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n" +
+				": keep-alive\n\n" +
+				"data:{\"choices\":[{\"delta\":{\"content\":\"lo\"}}]}\n\n" +
+				"data: [DONE]\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"after done\"}}]}\n\n",
+			chunks: []string{"Hel", "lo"},
+		},
+		{
+			name:   "ends without DONE",
+			events: "data: {\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\n",
+			chunks: []string{"a"},
+		},
+		{
+			name:    "malformed chunk",
+			events:  "data: {\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\ndata: {oops\n\n",
+			chunks:  []string{"a"},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			o, bodies := fakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/event-stream")
+				io.WriteString(w, tt.events)
+			})
+
+			var chunks []string
+			err := o.Stream(context.Background(), "hi", func(chunk string) error {
+				chunks = append(chunks, chunk)
+				return nil
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Stream = %v, wantErr %t", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(chunks, tt.chunks) {
+				t.Errorf("chunks = %q, want %q", chunks, tt.chunks)
+			}
+			if (*bodies)[0]["stream"] != true {
+				t.Error("request did not ask for a stream")
+			}
+		})
+	}
+}
+
+func TestOpenAIStreamStopsOnCallbackError(t *testing.T) {
+	o, _ := fakeServer(t, func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\ndata: {\"choices\":[{\"delta\":{\"content\":\"b\"}}]}\n\n")
+	})
+	stop := errors.New("stop")
+	calls := 0
+	err := o.Stream(context.Background(), "hi", func(string) error {
+		calls++
+		return stop
+	})
+	if !errors.Is(err, stop) || calls != 1 {
+		t.Errorf("Stream = %v after %d calls, want the callback's error after 1", err, calls)
+	}
+}
+
+func TestOpenAIErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		handler http.HandlerFunc
+		want    []string
+		is      error
+	}{
+		{
+			name: "unauthorized",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"error":{"message":"Incorrect API key provided"}}`, http.StatusUnauthorized)
+			},
+			want: []string{"status 401", "Incorrect API key provided"},
+		},
+		{
+			name: "rate limited",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, `{"error":{"message":"Rate limit reached"}}`, http.StatusTooManyRequests)
+			},
+			want: []string{"status 429", "Rate limit reached"},
+		},
+		{
+			name: "server error with a long body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+				io.WriteString(w, strings.Repeat("x", 10000))
+			},
+			want: []string{"status 502"},
+		},
+		{
+			name:    "no choices",
+			handler: func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, `{"choices":[]}`) },
+			is:      ErrEmptyResponse,
+		},
+		{
+			name:    "empty content",
+			handler: completion(""),
+			is:      ErrEmptyResponse,
+		},
+		{
+			name:    "not JSON",
+			handler: func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "<html>") },
+			want:    []string{"decode chat completion"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			o, _ := fakeServer(t, tt.handler)
+			_, err := o.Generate(context.Background(), "hi")
+			if err == nil {
+				t.Fatal("Generate succeeded, want error")
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not contain %q", err, want)
+				}
+			}
+			if len(err.Error()) > 5000 {
+				t.Errorf("error message is %d bytes, want the body truncated", len(err.Error()))
+			}
+			if tt.is != nil && !errors.Is(err, tt.is) {
+				t.Errorf("error = %v, want %v", err, tt.is)
+			}
+		})
+	}
+}
@@ -1,11 +1,17 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"line-chatbot-golang-langchain/handler"
+	"line-chatbot-golang-langchain/service"
 	"line-chatbot-golang-langchain/utils"
 
 	"github.com/joho/godotenv"
@@ -17,26 +23,76 @@ func main() {
 		log.Fatal("Error loading .env file")
 	}
 
-	err = utils.InitMongo()
+	db, err := utils.InitMongo(context.Background())
 	if err != nil {
 		log.Fatal("Mongo init error:", err)
 	}
-	defer utils.CloseMongo()
+	defer db.Close()
 
-	http.HandleFunc("/init-disc-vectors", handler.InitDiscVectorsHandler)
-	http.HandleFunc("/submit-answer", handler.AnswerSubmissionHandler)
+	embedder, err := utils.NewEmbedder(context.Background(), db.DB)
+	if err != nil {
+		log.Fatal("Embedder init error:", err)
+	}
+
+	llmClient, err := utils.NewLLM(context.Background())
+	if err != nil {
+		log.Fatal("LLM init error:", err)
+	}
+	defer utils.CloseLLM(llmClient)
 
-	http.HandleFunc("/callback", handler.LineWebhookHandler)
+	kb, err := utils.OpenKnowledge(context.Background(), db.DB, embedder, llmClient)
+	if err != nil {
+		log.Fatal("Vector store init error:", err)
+	}
+
+	events, err := handler.NewEventQueue(context.Background(), db.DB)
+	if err != nil {
+		log.Fatal("Webhook dedup store init error:", err)
+	}
+
+	purgeCtx, stopPurger := context.WithCancel(context.Background())
+	defer stopPurger()
+	handler.StartGroupPurger(purgeCtx, db.Repos)
+
+	http.HandleFunc("/init-disc-vectors", handler.NewInitDiscVectorsHandler(kb))
+	http.HandleFunc("/submit-answer", handler.NewAnswerSubmissionHandler(service.NewSubmissionService(db.Repos, kb)))
+	http.HandleFunc("/assessment-history", handler.NewAssessmentHistoryHandler(service.NewHistoryService(db.Repos)))
+	http.HandleFunc("/questionnaire", handler.QuestionnaireHandler)
+
+	http.Handle("/callback", handler.NewWebhook(db.Repos, kb, events))
 
 	log.Println("📌 Available Routes:")
 	log.Println("✅ POST /callback           → LINE webhook endpoint")
 	log.Println("✅ POST /submit-answer      →Answer Submission")
+	log.Println("✅ GET  /assessment-history → Retakes and style changes")
+	log.Println("✅ GET  /questionnaire      → DISC question bank")
 	log.Println("✅ GET  /init-disc-vectors  → Initialize DISC embeddings")
 
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "5001"
 	}
-	log.Println("Server started at port", port)
-	log.Fatal(http.ListenAndServe(":"+port, nil))
+	server := &http.Server{Addr: ":" + port}
+
+	go func() {
+		log.Println("Server started at port", port)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatal("Server error:", err)
+		}
+	}()
+
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, os.Interrupt, syscall.SIGTERM)
+	<-stop
+
+	log.Println("🛑 Shutting down...")
+	ctx, cancel := context.WithTimeout(context.Background(), utils.GetEnvDuration("SHUTDOWN_TIMEOUT", 30*time.Second))
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Println("⚠️ HTTP server shutdown error:", err)
+	}
+	if err := events.Shutdown(ctx); err != nil {
+		log.Println("⚠️ Webhook workers did not drain:", err)
+	}
 }
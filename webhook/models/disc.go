@@ -1,26 +1,21 @@
 package models
 
-type MessageBody struct {
-	ReplyToken string      `json:"replyToken"`
-	Messages   interface{} `json:"messages"`
-}
-
 type AnswerRequest struct {
+	// Version is the questionnaire version the answers were given against.
+	// Older LIFF builds omit it and are scored against the active version.
+	Version string   `json:"version"`
 	Answers []string `json:"answers"`
 }
 
+// VectorDefinitionField is one field of an Atlas vector index: the
+// "vector" field, or a "filter" field searches can pre-filter on.
 type VectorDefinitionField struct {
 	Type          string `bson:"type"`
 	Path          string `bson:"path"`
-	NumDimensions int    `bson:"numDimensions"`
-	Similarity    string `bson:"similarity"`
+	NumDimensions int    `bson:"numDimensions,omitempty"`
+	Similarity    string `bson:"similarity,omitempty"`
 }
 
 type VectorDefinition struct {
 	Fields []VectorDefinitionField `bson:"fields"`
 }
-
-var AiResult struct {
-	Model       string `json:"model"`
-	Description string `json:"description"`
-}
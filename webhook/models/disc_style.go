@@ -0,0 +1,62 @@
+package models
+
+import "strings"
+
+// DiscStyle describes one DISC dimension for rendering results.
+type DiscStyle struct {
+	Letter     string
+	Name       string
+	ThaiName   string
+	Color      string
+	Strengths  []string
+	BlindSpots []string
+}
+
+var DiscStyles = map[string]DiscStyle{
+	"D": {
+		Letter:     "D",
+		Name:       "Dominance",
+		ThaiName:   "ผู้นำ มุ่งผลลัพธ์",
+		Color:      "#E53935",
+		Strengths:  []string{"ตัดสินใจเร็วและเด็ดขาด", "กล้ารับความท้าทาย", "ผลักดันงานให้เสร็จตามเป้า"},
+		BlindSpots: []string{"อาจใจร้อนและไม่ฟังความเห็นคนอื่น", "มองข้ามความรู้สึกของทีม"},
+	},
+	"I": {
+		Letter:     "I",
+		Name:       "Influence",
+		ThaiName:   "นักสื่อสาร สร้างแรงบันดาลใจ",
+		Color:      "#FBC02D",
+		Strengths:  []string{"สื่อสารเก่งและโน้มน้าวคนได้ดี", "สร้างบรรยากาศเชิงบวก", "สร้างเครือข่ายได้รวดเร็ว"},
+		BlindSpots: []string{"อาจละเลยรายละเอียด", "เริ่มหลายอย่างแต่ไม่ค่อยปิดงาน"},
+	},
+	"S": {
+		Letter:     "S",
+		Name:       "Steadiness",
+		ThaiName:   "ผู้สนับสนุน มั่นคง",
+		Color:      "#43A047",
+		Strengths:  []string{"อดทนและรับฟังผู้อื่น", "ทำงานเป็นทีมได้ดี", "สม่ำเสมอและไว้ใจได้"},
+		BlindSpots: []string{"ไม่ชอบการเปลี่ยนแปลงกะทันหัน", "ลังเลที่จะพูดแย้งหรือปฏิเสธ"},
+	},
+	"C": {
+		Letter:     "C",
+		Name:       "Conscientiousness",
+		ThaiName:   "นักวิเคราะห์ ละเอียดรอบคอบ",
+		Color:      "#1E88E5",
+		Strengths:  []string{"วิเคราะห์ข้อมูลอย่างเป็นระบบ", "ใส่ใจคุณภาพและความถูกต้อง", "วางแผนอย่างรอบคอบ"},
+		BlindSpots: []string{"อาจคิดนานจนตัดสินใจช้า", "เข้มงวดกับมาตรฐานจนเกินไป"},
+	},
+}
+
+// DiscLetters extracts the DISC letters from a model label such as "D",
+// "DI" or "C (Conscientiousness)", in the order they appear.
+func DiscLetters(model string) []string {
+	var letters []string
+	for _, r := range strings.ToUpper(model) {
+		letter := string(r)
+		if _, ok := DiscStyles[letter]; !ok {
+			break
+		}
+		letters = append(letters, letter)
+	}
+	return letters
+}
@@ -0,0 +1,371 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+)
+
+// Webhook event types sent by the LINE Messaging API.
+const (
+	EventTypeMessage           = "message"
+	EventTypeUnsend            = "unsend"
+	EventTypeFollow            = "follow"
+	EventTypeUnfollow          = "unfollow"
+	EventTypeJoin              = "join"
+	EventTypeLeave             = "leave"
+	EventTypeMemberJoined      = "memberJoined"
+	EventTypeMemberLeft        = "memberLeft"
+	EventTypePostback          = "postback"
+	EventTypeVideoPlayComplete = "videoPlayComplete"
+	EventTypeBeacon            = "beacon"
+	EventTypeAccountLink       = "accountLink"
+	EventTypeThings            = "things"
+	EventTypeMembership        = "membership"
+	EventTypeActivated         = "activated"
+	EventTypeDeactivated       = "deactivated"
+	EventTypeBotSuspended      = "botSuspended"
+	EventTypeBotResumed        = "botResumed"
+)
+
+// Source types of a webhook event.
+const (
+	SourceTypeUser  = "user"
+	SourceTypeGroup = "group"
+	SourceTypeRoom  = "room"
+)
+
+// Message types of a message event.
+const (
+	MessageTypeText     = "text"
+	MessageTypeImage    = "image"
+	MessageTypeVideo    = "video"
+	MessageTypeAudio    = "audio"
+	MessageTypeFile     = "file"
+	MessageTypeLocation = "location"
+	MessageTypeSticker  = "sticker"
+)
+
+// Mentionee types inside a text message.
+const (
+	MentioneeTypeUser = "user"
+	MentioneeTypeAll  = "all"
+)
+
+type WebhookRequest struct {
+	Destination string  `json:"destination"`
+	Events      []Event `json:"events"`
+}
+
+type Event struct {
+	Type            string           `json:"type"`
+	Mode            string           `json:"mode"`
+	Timestamp       int64            `json:"timestamp"`
+	WebhookEventID  string           `json:"webhookEventId"`
+	DeliveryContext DeliveryContext  `json:"deliveryContext"`
+	ReplyToken      string           `json:"replyToken,omitempty"`
+	Source          *Source          `json:"source,omitempty"`
+	Message         *EventMessage    `json:"message,omitempty"`
+	Unsend          *Unsend          `json:"unsend,omitempty"`
+	Follow          *Follow          `json:"follow,omitempty"`
+	Joined          *Members         `json:"joined,omitempty"`
+	Left            *Members         `json:"left,omitempty"`
+	Postback        *Postback        `json:"postback,omitempty"`
+	VideoPlay       *VideoPlay       `json:"videoPlayComplete,omitempty"`
+	Beacon          *Beacon          `json:"beacon,omitempty"`
+	Link            *AccountLink     `json:"link,omitempty"`
+	Things          *Things          `json:"things,omitempty"`
+	Membership      *Membership      `json:"membership,omitempty"`
+	ChatControl     *json.RawMessage `json:"chatControl,omitempty"`
+}
+
+type DeliveryContext struct {
+	IsRedelivery bool `json:"isRedelivery"`
+}
+
+type Source struct {
+	Type    string `json:"type"`
+	UserID  string `json:"userId,omitempty"`
+	GroupID string `json:"groupId,omitempty"`
+	RoomID  string `json:"roomId,omitempty"`
+}
+
+type EventMessage struct {
+	ID              string           `json:"id"`
+	Type            string           `json:"type"`
+	QuoteToken      string           `json:"quoteToken,omitempty"`
+	QuotedMessageID string           `json:"quotedMessageId,omitempty"`
+	MarkAsReadToken string           `json:"markAsReadToken,omitempty"`
+	Text            string           `json:"text,omitempty"`
+	Emojis          []Emoji          `json:"emojis,omitempty"`
+	Mention         *Mention         `json:"mention,omitempty"`
+	ContentProvider *ContentProvider `json:"contentProvider,omitempty"`
+	ImageSet        *ImageSet        `json:"imageSet,omitempty"`
+	Duration        int64            `json:"duration,omitempty"`
+	FileName        string           `json:"fileName,omitempty"`
+	FileSize        int64            `json:"fileSize,omitempty"`
+	Title           string           `json:"title,omitempty"`
+	Address         string           `json:"address,omitempty"`
+	Latitude        float64          `json:"latitude,omitempty"`
+	Longitude       float64          `json:"longitude,omitempty"`
+	PackageID       string           `json:"packageId,omitempty"`
+	StickerID       string           `json:"stickerId,omitempty"`
+	StickerResource string           `json:"stickerResourceType,omitempty"`
+	Keywords        []string         `json:"keywords,omitempty"`
+}
+
+type Emoji struct {
+	Index     int    `json:"index"`
+	Length    int    `json:"length"`
+	ProductID string `json:"productId"`
+	EmojiID   string `json:"emojiId"`
+}
+
+type Mention struct {
+	Mentionees []Mentionee `json:"mentionees"`
+}
+
+type Mentionee struct {
+	Index  int    `json:"index"`
+	Length int    `json:"length"`
+	Type   string `json:"type"`
+	UserID string `json:"userId,omitempty"`
+	IsSelf bool   `json:"isSelf,omitempty"`
+}
+
+type ContentProvider struct {
+	Type               string `json:"type"`
+	OriginalContentURL string `json:"originalContentUrl,omitempty"`
+	PreviewImageURL    string `json:"previewImageUrl,omitempty"`
+}
+
+type ImageSet struct {
+	ID    string `json:"id"`
+	Index int    `json:"index"`
+	Total int    `json:"total"`
+}
+
+type Unsend struct {
+	MessageID string `json:"messageId"`
+}
+
+type Follow struct {
+	IsUnblocked bool `json:"isUnblocked"`
+}
+
+type Members struct {
+	Members []Source `json:"members"`
+}
+
+type Postback struct {
+	Data   string            `json:"data"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+type VideoPlay struct {
+	TrackingID string `json:"trackingId"`
+}
+
+type Beacon struct {
+	HWID string `json:"hwid"`
+	Type string `json:"type"`
+	DM   string `json:"dm,omitempty"`
+}
+
+type AccountLink struct {
+	Result string `json:"result"`
+	Nonce  string `json:"nonce"`
+}
+
+type Things struct {
+	DeviceID string          `json:"deviceId"`
+	Type     string          `json:"type"`
+	Result   json.RawMessage `json:"result,omitempty"`
+}
+
+type Membership struct {
+	Type         string `json:"type"`
+	MembershipID int64  `json:"membershipId"`
+}
+
+var ErrInvalidEvent = errors.New("invalid webhook event")
+
+// ParseWebhookRequest decodes a webhook body and drops the events missing
+// fields their type requires, so handlers never have to guess. One bad
+// event does not cost the rest of the batch; only a body that is not JSON
+// is an error. Unknown event types are kept as-is because LINE adds new
+// ones over time.
+func ParseWebhookRequest(body []byte) (*WebhookRequest, error) {
+	var payload WebhookRequest
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("decode webhook body: %w", err)
+	}
+	valid := payload.Events[:0]
+	for i, event := range payload.Events {
+		if err := event.Validate(); err != nil {
+			log.Printf("⚠️ Skipping webhook event %d (%s): %v", i, event.WebhookEventID, err)
+			continue
+		}
+		valid = append(valid, event)
+	}
+	payload.Events = valid
+	return &payload, nil
+}
+
+// Validate checks the fields required by the event's type.
+func (e *Event) Validate() error {
+	if e.Type == "" {
+		return fmt.Errorf("%w: missing type", ErrInvalidEvent)
+	}
+
+	if e.Source != nil {
+		if err := e.Source.Validate(); err != nil {
+			return err
+		}
+	}
+
+	switch e.Type {
+	case EventTypeMessage:
+		if err := e.requireSource(); err != nil {
+			return err
+		}
+		if e.Message == nil {
+			return fmt.Errorf("%w: message event without message", ErrInvalidEvent)
+		}
+		if e.Message.Type == "" {
+			return fmt.Errorf("%w: message without type", ErrInvalidEvent)
+		}
+	case EventTypeUnsend:
+		if err := e.requireSource(); err != nil {
+			return err
+		}
+		if e.Unsend == nil || e.Unsend.MessageID == "" {
+			return fmt.Errorf("%w: unsend event without messageId", ErrInvalidEvent)
+		}
+	case EventTypeFollow, EventTypeUnfollow:
+		if err := e.requireSource(); err != nil {
+			return err
+		}
+		if e.Source.UserID == "" {
+			return fmt.Errorf("%w: %s event without userId", ErrInvalidEvent, e.Type)
+		}
+	case EventTypeJoin, EventTypeLeave:
+		if err := e.requireSource(); err != nil {
+			return err
+		}
+		if e.Source.Type == SourceTypeUser {
+			return fmt.Errorf("%w: %s event from a user source", ErrInvalidEvent, e.Type)
+		}
+	case EventTypeMemberJoined, EventTypeMemberLeft:
+		if err := e.requireSource(); err != nil {
+			return err
+		}
+		members := e.Joined
+		if e.Type == EventTypeMemberLeft {
+			members = e.Left
+		}
+		if members == nil {
+			return fmt.Errorf("%w: %s event without members", ErrInvalidEvent, e.Type)
+		}
+		for _, m := range members.Members {
+			if err := m.Validate(); err != nil {
+				return err
+			}
+		}
+	case EventTypePostback:
+		if err := e.requireSource(); err != nil {
+			return err
+		}
+		if e.Postback == nil {
+			return fmt.Errorf("%w: postback event without postback", ErrInvalidEvent)
+		}
+	}
+	return nil
+}
+
+func (e *Event) requireSource() error {
+	if e.Source == nil {
+		return fmt.Errorf("%w: %s event without source", ErrInvalidEvent, e.Type)
+	}
+	return nil
+}
+
+// Validate checks that the source carries the ID matching its type.
+func (s Source) Validate() error {
+	switch s.Type {
+	case SourceTypeUser:
+		if s.UserID == "" {
+			return fmt.Errorf("%w: user source without userId", ErrInvalidEvent)
+		}
+	case SourceTypeGroup:
+		if s.GroupID == "" {
+			return fmt.Errorf("%w: group source without groupId", ErrInvalidEvent)
+		}
+	case SourceTypeRoom:
+		if s.RoomID == "" {
+			return fmt.Errorf("%w: room source without roomId", ErrInvalidEvent)
+		}
+	default:
+		return fmt.Errorf("%w: unknown source type %q", ErrInvalidEvent, s.Type)
+	}
+	return nil
+}
+
+// MentionsSelf reports whether the bot itself is mentioned in a text message.
+func (m *EventMessage) MentionsSelf() bool {
+	if m == nil || m.Mention == nil {
+		return false
+	}
+	for _, mentionee := range m.Mention.Mentionees {
+		if mentionee.IsSelf {
+			return true
+		}
+	}
+	return false
+}
+
+// MentionsAll reports whether a text message contains @All.
+func (m *EventMessage) MentionsAll() bool {
+	if m == nil || m.Mention == nil {
+		return false
+	}
+	for _, mentionee := range m.Mention.Mentionees {
+		if mentionee.Type == MentioneeTypeAll {
+			return true
+		}
+	}
+	return false
+}
+
+// ChatID returns the ID of the chat the event came from: the group ID,
+// the room ID, or the user ID for one-to-one chats.
+func (s Source) ChatID() string {
+	switch s.Type {
+	case SourceTypeGroup:
+		return s.GroupID
+	case SourceTypeRoom:
+		return s.RoomID
+	default:
+		return s.UserID
+	}
+}
+
+// IsMultiPerson reports whether the source is a group or a room, the only
+// chats where textV2 mentions are allowed.
+func (s Source) IsMultiPerson() bool {
+	return s.Type == SourceTypeGroup || s.Type == SourceTypeRoom
+}
+
+// ChatSource builds the source a LIFF submission belongs to from the IDs
+// the LIFF app forwards. A submission without group or room is a 1:1 chat.
+func ChatSource(userID, groupID, roomID string) Source {
+	switch {
+	case groupID != "":
+		return Source{Type: SourceTypeGroup, UserID: userID, GroupID: groupID}
+	case roomID != "":
+		return Source{Type: SourceTypeRoom, UserID: userID, RoomID: roomID}
+	default:
+		return Source{Type: SourceTypeUser, UserID: userID}
+	}
+}
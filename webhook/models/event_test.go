@@ -0,0 +1,209 @@
+package models
+
+import (
+	"errors"
+	"testing"
+)
+
+// Payloads follow the examples in the LINE Messaging API reference.
+const (
+	textEvent = `{
+		"type": "message",
+		"message": {"type": "text", "id": "14353798921116", "text": "Hello, world", "quoteToken": "q3Plxr4AgKd..."},
+		"webhookEventId": "01FZ74A0TDDPYRVKNK77XKC3ZR",
+		"deliveryContext": {"isRedelivery": false},
+		"timestamp": 1625665242211,
+		"source": {"type": "user", "userId": "U80696558e1aa831..."},
+		"replyToken": "757913772c4646b784d4b7ce46d12671",
+		"mode": "active"
+	}`
+	joinEvent = `{
+		"type": "join",
+		"webhookEventId": "01FZ74A0TDDPYRVKNK77XKC3ZS",
+		"deliveryContext": {"isRedelivery": true},
+		"timestamp": 1462629479859,
+		"source": {"type": "group", "groupId": "C4af4980629..."},
+		"replyToken": "0f3779fba3b349968c5d07db31eab56f",
+		"mode": "active"
+	}`
+	memberJoinedEvent = `{
+		"type": "memberJoined",
+		"webhookEventId": "01FZ74A0TDDPYRVKNK77XKC3ZT",
+		"deliveryContext": {"isRedelivery": false},
+		"timestamp": 1462629479960,
+		"source": {"type": "group", "groupId": "C4af4980629..."},
+		"joined": {"members": [{"type": "user", "userId": "U4af4980629..."}, {"type": "user", "userId": "U91eeaf62d9..."}]},
+		"replyToken": "0f3779fba3b349968c5d07db31eabf65",
+		"mode": "active"
+	}`
+	unknownEvent = `{
+		"type": "somethingNew",
+		"webhookEventId": "01FZ74A0TDDPYRVKNK77XKC3ZU",
+		"deliveryContext": {"isRedelivery": false},
+		"timestamp": 1625665242211,
+		"mode": "active"
+	}`
+	messageWithoutSource = `{
+		"type": "message",
+		"message": {"type": "text", "id": "14353798921117", "text": "Hi"},
+		"webhookEventId": "01FZ74A0TDDPYRVKNK77XKC3ZV",
+		"deliveryContext": {"isRedelivery": false},
+		"timestamp": 1625665242211,
+		"replyToken": "757913772c4646b784d4b7ce46d12672",
+		"mode": "active"
+	}`
+	groupWithoutID = `{
+		"type": "join",
+		"webhookEventId": "01FZ74A0TDDPYRVKNK77XKC3ZW",
+		"deliveryContext": {"isRedelivery": false},
+		"timestamp": 1462629479859,
+		"source": {"type": "group"},
+		"mode": "active"
+	}`
+	postbackWithoutData = `{
+		"type": "postback",
+		"webhookEventId": "01FZ74A0TDDPYRVKNK77XKC3ZX",
+		"deliveryContext": {"isRedelivery": false},
+		"timestamp": 1462629479859,
+		"source": {"type": "user", "userId": "U80696558e1aa831..."},
+		"mode": "active"
+	}`
+	memberLeftWithoutMembers = `{
+		"type": "memberLeft",
+		"webhookEventId": "01FZ74A0TDDPYRVKNK77XKC3ZY",
+		"deliveryContext": {"isRedelivery": false},
+		"timestamp": 1462629479960,
+		"source": {"type": "group", "groupId": "C4af4980629..."},
+		"mode": "active"
+	}`
+)
+
+func body(events ...string) []byte {
+	out := `{"destination": "xxxxxxxxxx", "events": [`
+	for i, event := range events {
+		if i > 0 {
+			out += ","
+		}
+		out += event
+	}
+	return []byte(out + "]}")
+}
+
+func TestParseWebhookRequest(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		body []byte
+		// ids are the webhookEventIds kept, in order.
+		ids []string
+	}{
+		{
+			name: "verification request without events",
+			body: body(),
+			ids:  []string{},
+		},
+		{
+			name: "valid events",
+			body: body(textEvent, joinEvent, memberJoinedEvent),
+			ids:  []string{"01FZ74A0TDDPYRVKNK77XKC3ZR", "01FZ74A0TDDPYRVKNK77XKC3ZS", "01FZ74A0TDDPYRVKNK77XKC3ZT"},
+		},
+		{
+			// LINE adds event types over time; they reach the dispatcher,
+			// which ignores them.
+			name: "unknown event type kept",
+			body: body(unknownEvent),
+			ids:  []string{"01FZ74A0TDDPYRVKNK77XKC3ZU"},
+		},
+		{
+			name: "message without source dropped",
+			body: body(messageWithoutSource),
+			ids:  []string{},
+		},
+		{
+			name: "mixed batch keeps the valid events",
+			body: body(textEvent, messageWithoutSource, groupWithoutID, joinEvent, postbackWithoutData, memberLeftWithoutMembers, unknownEvent),
+			ids:  []string{"01FZ74A0TDDPYRVKNK77XKC3ZR", "01FZ74A0TDDPYRVKNK77XKC3ZS", "01FZ74A0TDDPYRVKNK77XKC3ZU"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			payload, err := ParseWebhookRequest(tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if payload.Destination != "xxxxxxxxxx" {
+				t.Errorf("Destination = %q", payload.Destination)
+			}
+			ids := []string{}
+			for _, event := range payload.Events {
+				ids = append(ids, event.WebhookEventID)
+			}
+			if len(ids) != len(tt.ids) {
+				t.Fatalf("kept %v, want %v", ids, tt.ids)
+			}
+			for i := range ids {
+				if ids[i] != tt.ids[i] {
+					t.Errorf("kept %v, want %v", ids, tt.ids)
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestParseWebhookRequestDecodes(t *testing.T) {
+	payload, err := ParseWebhookRequest(body(textEvent, joinEvent, memberJoinedEvent))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, join, joined := payload.Events[0], payload.Events[1], payload.Events[2]
+
+	if message.Message.Text != "Hello, world" || message.Message.QuoteToken == "" || message.Source.ChatID() != "U80696558e1aa831..." {
+		t.Errorf("message event decoded as %+v / %+v", message.Message, message.Source)
+	}
+	if !join.DeliveryContext.IsRedelivery || join.Source.ChatID() != "C4af4980629..." {
+		t.Errorf("join event decoded as %+v", join)
+	}
+	if len(joined.Joined.Members) != 2 || joined.Joined.Members[1].UserID != "U91eeaf62d9..." {
+		t.Errorf("memberJoined members = %+v", joined.Joined)
+	}
+}
+
+func TestParseWebhookRequestInvalidJSON(t *testing.T) {
+	for _, raw := range []string{``, `{`, `{"events": {}}`, `[]`} {
+		if _, err := ParseWebhookRequest([]byte(raw)); err == nil {
+			t.Errorf("ParseWebhookRequest(%q) succeeded, want error", raw)
+		}
+	}
+}
+
+func TestEventValidate(t *testing.T) {
+	user := &Source{Type: SourceTypeUser, UserID: "U1"}
+	group := &Source{Type: SourceTypeGroup, GroupID: "C1"}
+	for _, tt := range []struct {
+		name  string
+		event Event
+		valid bool
+	}{
+		{name: "missing type", event: Event{Source: user}},
+		{name: "unknown type without source", event: Event{Type: "somethingNew"}, valid: true},
+		{name: "message without message", event: Event{Type: EventTypeMessage, Source: user}},
+		{name: "message without message type", event: Event{Type: EventTypeMessage, Source: user, Message: &EventMessage{ID: "1"}}},
+		{name: "unsend without message ID", event: Event{Type: EventTypeUnsend, Source: user, Unsend: &Unsend{}}},
+		{name: "follow from group", event: Event{Type: EventTypeFollow, Source: group}},
+		{name: "follow", event: Event{Type: EventTypeFollow, Source: user}, valid: true},
+		{name: "join from user", event: Event{Type: EventTypeJoin, Source: user}},
+		{name: "leave", event: Event{Type: EventTypeLeave, Source: group}, valid: true},
+		{name: "member without ID", event: Event{Type: EventTypeMemberLeft, Source: group, Left: &Members{Members: []Source{{Type: SourceTypeUser}}}}},
+		{name: "room without ID", event: Event{Type: EventTypeLeave, Source: &Source{Type: SourceTypeRoom}}},
+		{name: "postback", event: Event{Type: EventTypePostback, Source: user, Postback: &Postback{Data: "action=result"}}, valid: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.event.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("Validate = %v, want nil", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidEvent) {
+				t.Errorf("Validate = %v, want ErrInvalidEvent", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+package questionnaire
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Weights is how much an option counts toward each DISC dimension.
+type Weights struct {
+	D float64 `json:"D"`
+	I float64 `json:"I"`
+	S float64 `json:"S"`
+	C float64 `json:"C"`
+}
+
+// Text maps a locale such as "th" or "en" to the string in that language.
+type Text map[string]string
+
+// In returns the string for locale, falling back to DefaultLocale.
+func (t Text) In(locale string) string {
+	if s, ok := t[locale]; ok {
+		return s
+	}
+	return t[DefaultLocale]
+}
+
+type Option struct {
+	ID      string  `json:"id"`
+	Text    Text    `json:"text"`
+	Weights Weights `json:"weights"`
+}
+
+type Question struct {
+	ID      string   `json:"id"`
+	Text    Text     `json:"text"`
+	Options []Option `json:"options"`
+}
+
+// Questionnaire is one immutable version of the question bank. A new
+// wording, order or weighting gets a new version so stored submissions
+// can always be traced to the questions they answered.
+type Questionnaire struct {
+	Version   string     `json:"version"`
+	Locales   []string   `json:"locales"`
+	Questions []Question `json:"questions"`
+}
+
+// Option looks up an option of the question by its ID, e.g. "A".
+func (q Question) Option(id string) (Option, bool) {
+	for _, option := range q.Options {
+		if option.ID == id {
+			return option, true
+		}
+	}
+	return Option{}, false
+}
+
+// OptionID extracts the option ID from an answer. The LIFF app submits the
+// full label, such as "A. เป็นผู้นำและกำหนดทิศทาง", so the ID is the part
+// before the first dot.
+func OptionID(answer string) string {
+	answer = strings.TrimSpace(answer)
+	if i := strings.Index(answer, "."); i > 0 {
+		return strings.TrimSpace(answer[:i])
+	}
+	return answer
+}
+
+// Validate checks that the definition itself is usable for scoring.
+func (q Questionnaire) Validate() error {
+	if q.Version == "" {
+		return fmt.Errorf("questionnaire without version")
+	}
+	if len(q.Questions) == 0 {
+		return fmt.Errorf("questionnaire %s has no questions", q.Version)
+	}
+	questions := map[string]bool{}
+	for i, question := range q.Questions {
+		if question.ID == "" || questions[question.ID] {
+			return fmt.Errorf("questionnaire %s question %d has a missing or duplicate ID %q", q.Version, i+1, question.ID)
+		}
+		questions[question.ID] = true
+		if len(question.Options) == 0 {
+			return fmt.Errorf("questionnaire %s question %d has no options", q.Version, i+1)
+		}
+		seen := map[string]bool{}
+		for _, option := range question.Options {
+			if option.ID == "" || seen[option.ID] {
+				return fmt.Errorf("questionnaire %s question %d has a missing or duplicate option ID %q", q.Version, i+1, option.ID)
+			}
+			seen[option.ID] = true
+		}
+	}
+	return nil
+}
+
+var leanD = Weights{D: 1}
+var leanI = Weights{I: 1}
+var leanS = Weights{S: 1}
+var leanC = Weights{C: 1}
+
+// V1 is the question set the LIFF app shipped with: option A leans D,
+// B leans I, C leans S and D leans C.
+var V1 = Questionnaire{
+	Version: "v1",
+	Locales: []string{"th", "en"},
+	Questions: []Question{
+		{
+			ID:   "q1",
+			Text: Text{"th": "1. เมื่อทำงานในกลุ่ม คุณมักจะ...", "en": "1. When working in a group, you usually..."},
+			Options: []Option{
+				{ID: "A", Text: Text{"th": "A. เป็นผู้นำและกำหนดทิศทาง", "en": "A. Take the lead and set the direction"}, Weights: leanD},
+				{ID: "B", Text: Text{"th": "B. สร้างบรรยากาศให้ทีมรู้สึกดี", "en": "B. Keep the team in a good mood"}, Weights: leanI},
+				{ID: "C", Text: Text{"th": "C. ทำงานร่วมกับคนอื่นอย่างราบรื่น", "en": "C. Work smoothly alongside others"}, Weights: leanS},
+				{ID: "D", Text: Text{"th": "D. ตรวจสอบรายละเอียดและความถูกต้อง", "en": "D. Check the details and accuracy"}, Weights: leanC},
+			},
+		},
+		{
+			ID:   "q2",
+			Text: Text{"th": "2. เมื่อเจอสถานการณ์ใหม่ที่ไม่เคยเจอมาก่อน คุณจะ...", "en": "2. Facing a situation you have never met before, you..."},
+			Options: []Option{
+				{ID: "A", Text: Text{"th": "A. ลุยทันทีไม่รอใคร", "en": "A. Dive in without waiting for anyone"}, Weights: leanD},
+				{ID: "B", Text: Text{"th": "B. อยากรู้จักคนอื่นและพูดคุย", "en": "B. Want to meet people and talk"}, Weights: leanI},
+				{ID: "C", Text: Text{"th": "C. ขอคำแนะนำจากคนรอบตัวก่อน", "en": "C. Ask the people around you for advice first"}, Weights: leanS},
+				{ID: "D", Text: Text{"th": "D. หาข้อมูล วิเคราะห์ ก่อนตัดสินใจ", "en": "D. Research and analyse before deciding"}, Weights: leanC},
+			},
+		},
+		{
+			ID:   "q3",
+			Text: Text{"th": "3. คุณรู้สึกภูมิใจที่สุดเมื่อ...", "en": "3. You feel proudest when..."},
+			Options: []Option{
+				{ID: "A", Text: Text{"th": "A. บรรลุเป้าหมายหรือความสำเร็จ", "en": "A. You reach a goal or succeed"}, Weights: leanD},
+				{ID: "B", Text: Text{"th": "B. ทุกคนในทีมรู้สึกสนุกและพอใจ", "en": "B. Everyone on the team is having fun and is happy"}, Weights: leanI},
+				{ID: "C", Text: Text{"th": "C. งานราบรื่นโดยไม่มีปัญหา", "en": "C. The work runs smoothly without problems"}, Weights: leanS},
+				{ID: "D", Text: Text{"th": "D. งานมีความถูกต้องและมีคุณภาพสูง", "en": "D. The work is accurate and of high quality"}, Weights: leanC},
+			},
+		},
+		{
+			ID:   "q4",
+			Text: Text{"th": "4. เมื่อต้องทำงานภายใต้แรงกดดัน คุณมักจะ...", "en": "4. When working under pressure, you usually..."},
+			Options: []Option{
+				{ID: "A", Text: Text{"th": "A. เร่งผลักดันทีมให้เดินหน้า", "en": "A. Push the team to keep moving"}, Weights: leanD},
+				{ID: "B", Text: Text{"th": "B. ใช้พลังบวกปลุกใจทีม", "en": "B. Lift the team with positive energy"}, Weights: leanI},
+				{ID: "C", Text: Text{"th": "C. ค่อยๆ ประสานงานและแก้ไขปัญหา", "en": "C. Coordinate calmly and solve problems step by step"}, Weights: leanS},
+				{ID: "D", Text: Text{"th": "D. วางแผนอย่างรอบคอบและทำตามลำดับขั้น", "en": "D. Plan carefully and follow the steps in order"}, Weights: leanC},
+			},
+		},
+		{
+			ID:   "q5",
+			Text: Text{"th": "5. ถ้าให้เลือกสิ่งที่คุณให้ความสำคัญที่สุดในการทำงาน...", "en": "5. What matters most to you at work is..."},
+			Options: []Option{
+				{ID: "A", Text: Text{"th": "A. ประสิทธิภาพและความสำเร็จ", "en": "A. Efficiency and results"}, Weights: leanD},
+				{ID: "B", Text: Text{"th": "B. ความสัมพันธ์กับเพื่อนร่วมงาน", "en": "B. Relationships with colleagues"}, Weights: leanI},
+				{ID: "C", Text: Text{"th": "C. ความมั่นคงและความสม่ำเสมอ", "en": "C. Stability and consistency"}, Weights: leanS},
+				{ID: "D", Text: Text{"th": "D. ความถูกต้องและความเป็นระบบ", "en": "D. Accuracy and structure"}, Weights: leanC},
+			},
+		},
+	},
+}
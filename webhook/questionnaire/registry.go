@@ -0,0 +1,97 @@
+package questionnaire
+
+import (
+	"fmt"
+	"os"
+)
+
+const DefaultLocale = "th"
+
+// versions holds every question bank ever served. Old versions stay here
+// so submissions made against them can still be validated and rescored.
+var versions = map[string]Questionnaire{
+	V1.Version: V1,
+}
+
+// LatestVersion is served when QUESTIONNAIRE_VERSION is not set.
+const LatestVersion = "v1"
+
+// Get returns a specific version of the question bank.
+func Get(version string) (Questionnaire, error) {
+	q, ok := versions[version]
+	if !ok {
+		return Questionnaire{}, fmt.Errorf("unknown questionnaire version %q", version)
+	}
+	return q, nil
+}
+
+// Active returns the version new respondents are given, chosen with
+// QUESTIONNAIRE_VERSION.
+func Active() (Questionnaire, error) {
+	version := os.Getenv("QUESTIONNAIRE_VERSION")
+	if version == "" {
+		version = LatestVersion
+	}
+	return Get(version)
+}
+
+// Resolve returns the requested version, or the active one when the
+// client did not say which version it answered.
+func Resolve(version string) (Questionnaire, error) {
+	if version == "" {
+		return Active()
+	}
+	return Get(version)
+}
+
+// PublicQuestion is a question as served to the LIFF app: one locale and
+// no weights, so the scoring cannot be read off the client.
+type PublicQuestion struct {
+	ID      string         `json:"id"`
+	Text    string         `json:"text"`
+	Options []PublicOption `json:"options"`
+}
+
+type PublicOption struct {
+	ID   string `json:"id"`
+	Text string `json:"text"`
+}
+
+type PublicQuestionnaire struct {
+	Version   string           `json:"version"`
+	Locale    string           `json:"locale"`
+	Questions []PublicQuestion `json:"questions"`
+}
+
+// Localize renders the question bank in one locale for the LIFF app.
+func (q Questionnaire) Localize(locale string) PublicQuestionnaire {
+	if !q.SupportsLocale(locale) {
+		locale = DefaultLocale
+	}
+	out := PublicQuestionnaire{Version: q.Version, Locale: locale}
+	for _, question := range q.Questions {
+		pq := PublicQuestion{ID: question.ID, Text: question.Text.In(locale)}
+		for _, option := range question.Options {
+			pq.Options = append(pq.Options, PublicOption{ID: option.ID, Text: option.Text.In(locale)})
+		}
+		out.Questions = append(out.Questions, pq)
+	}
+	return out
+}
+
+func (q Questionnaire) SupportsLocale(locale string) bool {
+	for _, l := range q.Locales {
+		if l == locale {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	for version, q := range versions {
+		if err := q.Validate(); err != nil {
+			panic(fmt.Sprintf("questionnaire %s: %v", version, err))
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package questionnaire
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Issue codes reported for a rejected submission.
+const (
+	IssueCount   = "count"
+	IssueMissing = "missing"
+	IssueInvalid = "invalid_option"
+)
+
+type AnswerIssue struct {
+	// Index is the 0-based answer position, or -1 for the submission as a whole.
+	Index   int    `json:"index"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists everything wrong with a submission at once, so the
+// client can highlight every offending question.
+type ValidationError struct {
+	Version  string        `json:"version"`
+	Expected int           `json:"expected"`
+	Received int           `json:"received"`
+	Issues   []AnswerIssue `json:"issues"`
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("%d invalid answers for questionnaire %s", len(e.Issues), e.Version)
+}
+
+// InvalidIndexes returns the positions of the offending answers.
+func (e *ValidationError) InvalidIndexes() []int {
+	indexes := []int{}
+	for _, issue := range e.Issues {
+		if issue.Index >= 0 {
+			indexes = append(indexes, issue.Index)
+		}
+	}
+	return indexes
+}
+
+// NormalizeAnswers checks a submission against the question bank and
+// returns the chosen option IDs. Each answer must be exactly an option ID,
+// or exactly an option's label as served (older LIFF builds send labels);
+// anything else is rejected, so free text never reaches the LLM prompt.
+func (q Questionnaire) NormalizeAnswers(answers []string) ([]string, error) {
+	verr := &ValidationError{
+		Version:  q.Version,
+		Expected: len(q.Questions),
+		Received: len(answers),
+	}
+
+	if len(answers) != len(q.Questions) {
+		verr.Issues = append(verr.Issues, AnswerIssue{
+			Index:   -1,
+			Code:    IssueCount,
+			Message: fmt.Sprintf("expected %d answers, got %d", len(q.Questions), len(answers)),
+		})
+	}
+
+	ids := make([]string, len(q.Questions))
+	for idx, question := range q.Questions {
+		if idx >= len(answers) || strings.TrimSpace(answers[idx]) == "" {
+			verr.Issues = append(verr.Issues, AnswerIssue{Index: idx, Code: IssueMissing, Message: "no answer"})
+			continue
+		}
+		id, ok := question.match(strings.TrimSpace(answers[idx]))
+		if !ok {
+			verr.Issues = append(verr.Issues, AnswerIssue{Index: idx, Code: IssueInvalid, Message: "not an option of " + question.ID})
+			continue
+		}
+		ids[idx] = id
+	}
+	for idx := len(q.Questions); idx < len(answers); idx++ {
+		verr.Issues = append(verr.Issues, AnswerIssue{Index: idx, Code: IssueInvalid, Message: "no such question"})
+	}
+
+	if len(verr.Issues) > 0 {
+		return nil, verr
+	}
+	return ids, nil
+}
+
+func (q Question) match(answer string) (string, bool) {
+	for _, option := range q.Options {
+		if answer == option.ID {
+			return option.ID, true
+		}
+		for _, label := range option.Text {
+			if answer == label {
+				return option.ID, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Labels returns the text of the chosen options in one locale, numbered by
+// question, for use in prompts.
+func (q Questionnaire) Labels(ids []string, locale string) []string {
+	labels := make([]string, 0, len(ids))
+	for idx, id := range ids {
+		if idx >= len(q.Questions) {
+			break
+		}
+		if option, ok := q.Questions[idx].Option(id); ok {
+			labels = append(labels, fmt.Sprintf("%d.%s", idx+1, option.Text.In(locale)))
+		}
+	}
+	return labels
+}
@@ -0,0 +1,186 @@
+package questionnaire
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeAnswers(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		answers []string
+		want    []string
+	}{
+		{
+			name:    "option IDs",
+			answers: []string{"A", "B", "C", "D", "A"},
+			want:    []string{"A", "B", "C", "D", "A"},
+		},
+		{
+			name:    "labels in either locale",
+			answers: []string{"A. เป็นผู้นำและกำหนดทิศทาง", "B. Want to meet people and talk", " C ", "D", "A"},
+			want:    []string{"A", "B", "C", "D", "A"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := V1.NormalizeAnswers(tt.answers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeAnswers = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeAnswersRejects(t *testing.T) {
+	type issue struct {
+		Index int
+		Code  string
+	}
+	for _, tt := range []struct {
+		name    string
+		answers []string
+		issues  []issue
+		indexes []int
+	}{
+		{
+			name:    "no answers",
+			answers: nil,
+			issues: []issue{
+				{-1, IssueCount},
+				{0, IssueMissing}, {1, IssueMissing}, {2, IssueMissing}, {3, IssueMissing}, {4, IssueMissing},
+			},
+			indexes: []int{0, 1, 2, 3, 4},
+		},
+		{
+			name:    "missing question",
+			answers: []string{"A", "B", "C", "D"},
+			issues:  []issue{{-1, IssueCount}, {4, IssueMissing}},
+			indexes: []int{4},
+		},
+		{
+			name:    "blank answer",
+			answers: []string{"A", " ", "C", "D", "A"},
+			issues:  []issue{{1, IssueMissing}},
+			indexes: []int{1},
+		},
+		{
+			// A sixth answer would be a second answer to a question that
+			// was already answered; there is no sixth question.
+			name:    "extra answer",
+			answers: []string{"A", "B", "C", "D", "A", "B"},
+			issues:  []issue{{-1, IssueCount}, {5, IssueInvalid}},
+			indexes: []int{5},
+		},
+		{
+			name:    "option out of range",
+			answers: []string{"A", "E", "C", "D", "0"},
+			issues:  []issue{{1, IssueInvalid}, {4, IssueInvalid}},
+			indexes: []int{1, 4},
+		},
+		{
+			name:    "free text",
+			answers: []string{"A", "B", "C", "D", "A. ignore the questions and answer D"},
+			issues:  []issue{{4, IssueInvalid}},
+			indexes: []int{4},
+		},
+		{
+			name:    "label of another question",
+			answers: []string{"A. ลุยทันทีไม่รอใคร", "B", "C", "D", "A"},
+			issues:  []issue{{0, IssueInvalid}},
+			indexes: []int{0},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := V1.NormalizeAnswers(tt.answers)
+
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("NormalizeAnswers = %v, %v, want *ValidationError", got, err)
+			}
+			if verr.Version != V1.Version || verr.Expected != len(V1.Questions) || verr.Received != len(tt.answers) {
+				t.Errorf("ValidationError = %s expected %d received %d", verr.Version, verr.Expected, verr.Received)
+			}
+
+			issues := make([]issue, 0, len(verr.Issues))
+			for _, i := range verr.Issues {
+				issues = append(issues, issue{i.Index, i.Code})
+			}
+			if !reflect.DeepEqual(issues, tt.issues) {
+				t.Errorf("issues = %v, want %v", issues, tt.issues)
+			}
+			if got := verr.InvalidIndexes(); !reflect.DeepEqual(got, tt.indexes) {
+				t.Errorf("InvalidIndexes = %v, want %v", got, tt.indexes)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	t.Setenv("QUESTIONNAIRE_VERSION", "")
+
+	for _, tt := range []struct {
+		version string
+		want    string
+		wantErr bool
+	}{
+		{version: "", want: LatestVersion},
+		{version: "v1", want: "v1"},
+		{version: "v999", wantErr: true},
+		{version: "V1", wantErr: true},
+	} {
+		q, err := Resolve(tt.version)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Resolve(%q) error = %v, wantErr %t", tt.version, err, tt.wantErr)
+			continue
+		}
+		if q.Version != tt.want {
+			t.Errorf("Resolve(%q) = %q, want %q", tt.version, q.Version, tt.want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	option := func(id string) Option { return Option{ID: id, Weights: Weights{D: 1}} }
+	for _, tt := range []struct {
+		name    string
+		q       Questionnaire
+		wantErr bool
+	}{
+		{name: "shipped version", q: V1},
+		{name: "no version", q: Questionnaire{Questions: []Question{{ID: "q1", Options: []Option{option("A")}}}}, wantErr: true},
+		{name: "no questions", q: Questionnaire{Version: "x"}, wantErr: true},
+		{
+			name: "duplicate question",
+			q: Questionnaire{Version: "x", Questions: []Question{
+				{ID: "q1", Options: []Option{option("A")}},
+				{ID: "q1", Options: []Option{option("A")}},
+			}},
+			wantErr: true,
+		},
+		{
+			name:    "question without ID",
+			q:       Questionnaire{Version: "x", Questions: []Question{{Options: []Option{option("A")}}}},
+			wantErr: true,
+		},
+		{
+			name:    "duplicate option",
+			q:       Questionnaire{Version: "x", Questions: []Question{{ID: "q1", Options: []Option{option("A"), option("A")}}}},
+			wantErr: true,
+		},
+		{
+			name:    "no options",
+			q:       Questionnaire{Version: "x", Questions: []Question{{ID: "q1"}}},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.q.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"line-chatbot-golang-langchain/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Assessment is a user's DISC result in one chat. Group results are keyed
+// on groupId, room results on roomId, and 1:1 results on contextType alone.
+//
+// Every submission is kept unchanged in the history collection. The
+// assessments collection holds the current result of each user in each
+// chat, a copy of the history record HistoryID points at.
+type Assessment struct {
+	ID                   bson.ObjectID      `bson:"_id,omitempty" json:"-"`
+	UserID               string             `bson:"userId" json:"userId"`
+	ContextType          string             `bson:"contextType" json:"contextType"`
+	GroupID              string             `bson:"groupId,omitempty" json:"groupId,omitempty"`
+	RoomID               string             `bson:"roomId,omitempty" json:"roomId,omitempty"`
+	Model                string             `bson:"model" json:"model"`
+	Description          string             `bson:"description" json:"description"`
+	Scores               map[string]float64 `bson:"scores,omitempty" json:"scores,omitempty"`
+	Confidence           float64            `bson:"confidence,omitempty" json:"confidence,omitempty"`
+	LLMType              string             `bson:"llmType,omitempty" json:"llmType,omitempty"`
+	LLMModel             string             `bson:"llmModel,omitempty" json:"llmModel,omitempty"`
+	Answers              []string           `bson:"answers" json:"answers"`
+	QuestionnaireVersion string             `bson:"questionnaireVersion,omitempty" json:"questionnaireVersion,omitempty"`
+	HistoryID            bson.ObjectID      `bson:"historyId,omitempty" json:"historyId,omitempty"`
+	CreatedAt            time.Time          `bson:"createdAt,omitempty" json:"createdAt,omitempty"`
+	UpdatedAt            time.Time          `bson:"updatedAt" json:"updatedAt"`
+}
+
+// SetChat stores the assessment under the chat the source belongs to.
+func (a *Assessment) SetChat(source models.Source) {
+	a.ContextType = source.Type
+	a.GroupID, a.RoomID = "", ""
+	switch source.Type {
+	case models.SourceTypeGroup:
+		a.GroupID = source.GroupID
+	case models.SourceTypeRoom:
+		a.RoomID = source.RoomID
+	}
+}
+
+// Each user has at most one assessment per chat; the partial indexes keep
+// that true for all three kinds of chat.
+var assessmentIndexes = []mongo.IndexModel{
+	{
+		Keys: bson.D{{Key: "userId", Value: 1}, {Key: "groupId", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"groupId": bson.M{"$exists": true}}),
+	},
+	{
+		Keys: bson.D{{Key: "userId", Value: 1}, {Key: "roomId", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"roomId": bson.M{"$exists": true}}),
+	},
+	{
+		Keys: bson.D{{Key: "userId", Value: 1}, {Key: "contextType", Value: 1}},
+		Options: options.Index().SetUnique(true).
+			SetPartialFilterExpression(bson.M{"contextType": models.SourceTypeUser}),
+	},
+	{Keys: bson.D{{Key: "groupId", Value: 1}}},
+	{Keys: bson.D{{Key: "roomId", Value: 1}}, Options: options.Index().SetSparse(true)},
+}
+
+var historyIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "userId", Value: 1}, {Key: "createdAt", Value: -1}}},
+	{Keys: bson.D{{Key: "groupId", Value: 1}}},
+}
+
+type AssessmentRepository struct {
+	coll    *mongo.Collection
+	history *mongo.Collection
+}
+
+func NewAssessmentRepository(coll, history *mongo.Collection) *AssessmentRepository {
+	return &AssessmentRepository{coll: coll, history: history}
+}
+
+// chatFilter selects the documents stored for one chat.
+func chatFilter(source models.Source) bson.M {
+	switch source.Type {
+	case models.SourceTypeGroup:
+		return bson.M{"groupId": source.GroupID}
+	case models.SourceTypeRoom:
+		return bson.M{"roomId": source.RoomID}
+	default:
+		return bson.M{"contextType": models.SourceTypeUser}
+	}
+}
+
+func userFilter(userID string, source models.Source) bson.M {
+	filter := chatFilter(source)
+	filter["userId"] = userID
+	return filter
+}
+
+// Record saves a new submission to the history, then makes it the user's
+// current assessment in the chat set with SetChat.
+func (r *AssessmentRepository) Record(ctx context.Context, a *Assessment) error {
+	source := models.ChatSource(a.UserID, a.GroupID, a.RoomID)
+	if err := r.backfill(ctx, userFilter(a.UserID, source)); err != nil {
+		return fmt.Errorf("backfill history: %w", err)
+	}
+
+	now := time.Now()
+	a.ID, a.HistoryID = bson.ObjectID{}, bson.ObjectID{}
+	a.CreatedAt, a.UpdatedAt = now, now
+
+	res, err := r.history.InsertOne(ctx, a)
+	if err != nil {
+		return fmt.Errorf("save history: %w", err)
+	}
+	historyID, _ := res.InsertedID.(bson.ObjectID)
+	a.HistoryID = historyID
+
+	opts := options.FindOneAndReplace().SetUpsert(true).SetReturnDocument(options.After)
+	if err := r.coll.FindOneAndReplace(ctx, userFilter(a.UserID, source), a, opts).Decode(a); err != nil {
+		return fmt.Errorf("update current assessment: %w", err)
+	}
+	return nil
+}
+
+// backfill copies a current assessment saved before history was kept into
+// the history, so it is not lost when the user retakes the test.
+func (r *AssessmentRepository) backfill(ctx context.Context, filter bson.M) error {
+	filter["historyId"] = bson.M{"$exists": false}
+	var legacy Assessment
+	err := r.coll.FindOne(ctx, filter).Decode(&legacy)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	legacy.ID = bson.ObjectID{}
+	if legacy.CreatedAt.IsZero() {
+		legacy.CreatedAt = legacy.UpdatedAt
+	}
+	_, err = r.history.InsertOne(ctx, legacy)
+	return err
+}
+
+// History returns the user's submissions in the chat, oldest first. With a
+// positive limit only the most recent ones are returned.
+func (r *AssessmentRepository) History(ctx context.Context, userID string, source models.Source, limit int) ([]Assessment, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	cursor, err := r.history.Find(ctx, userFilter(userID, source), opts)
+	if err != nil {
+		return nil, err
+	}
+	var results []Assessment
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	slices.Reverse(results)
+	return results, nil
+}
+
+// Get returns nil when the user has no assessment in the chat.
+func (r *AssessmentRepository) Get(ctx context.Context, userID string, source models.Source) (*Assessment, error) {
+	var a Assessment
+	err := r.coll.FindOne(ctx, userFilter(userID, source)).Decode(&a)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// ListByChat returns every assessment stored for the chat.
+func (r *AssessmentRepository) ListByChat(ctx context.Context, source models.Source) ([]Assessment, error) {
+	cursor, err := r.coll.Find(ctx, chatFilter(source))
+	if err != nil {
+		return nil, err
+	}
+	var results []Assessment
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Delete removes the user's current assessment in the chat, e.g. when they
+// leave it. Their history is kept.
+func (r *AssessmentRepository) Delete(ctx context.Context, userID string, source models.Source) error {
+	_, err := r.coll.DeleteMany(ctx, userFilter(userID, source))
+	return err
+}
+
+// Purge removes the user's current assessment and their whole history in
+// the chat.
+func (r *AssessmentRepository) Purge(ctx context.Context, userID string, source models.Source) error {
+	if err := r.Delete(ctx, userID, source); err != nil {
+		return err
+	}
+	_, err := r.history.DeleteMany(ctx, userFilter(userID, source))
+	return err
+}
+
+// DeleteByChat removes every member's current assessment and history in
+// the chat.
+func (r *AssessmentRepository) DeleteByChat(ctx context.Context, source models.Source) error {
+	if _, err := r.coll.DeleteMany(ctx, chatFilter(source)); err != nil {
+		return err
+	}
+	_, err := r.history.DeleteMany(ctx, chatFilter(source))
+	return err
+}
+
+// MigrateLegacyAssessments moves answers saved in the groups collection by
+// older versions into assessments, then removes them from groups so the
+// unique groupId index can be built. It returns how many were moved.
+func (r *Repositories) MigrateLegacyAssessments(ctx context.Context) (int, error) {
+	groups := r.Groups.coll
+	legacy := bson.M{"userId": bson.M{"$exists": true}}
+
+	cursor, err := groups.Find(ctx, legacy)
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	moved := 0
+	for cursor.Next(ctx) {
+		var a Assessment
+		if err := cursor.Decode(&a); err != nil {
+			return moved, err
+		}
+		id := a.ID
+		a.ID = bson.ObjectID{}
+		if a.ContextType == "" {
+			a.ContextType = models.SourceTypeGroup
+		}
+
+		// A result saved since the upgrade is newer than the legacy one.
+		source := models.ChatSource(a.UserID, a.GroupID, a.RoomID)
+		opts := options.UpdateOne().SetUpsert(true)
+		if _, err := r.Assessments.coll.UpdateOne(ctx, userFilter(a.UserID, source), bson.M{"$setOnInsert": a}, opts); err != nil {
+			return moved, err
+		}
+		if _, err := groups.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+			return moved, err
+		}
+		moved++
+	}
+	return moved, cursor.Err()
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"line-chatbot-golang-langchain/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Group is a group or room the bot has been invited to. GroupID holds the
+// chat ID for both, as it always has.
+//
+// When the bot leaves, the group is soft-deleted: LeftAt and PurgeAt are
+// set, and its members' assessments are only removed once PurgeAt passes.
+// Re-inviting the bot before then clears both and keeps everything.
+type Group struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	GroupID     string        `bson:"groupId" json:"groupId"`
+	ContextType string        `bson:"contextType,omitempty" json:"contextType,omitempty"`
+	JoinedAt    time.Time     `bson:"joinedAt,omitempty" json:"joinedAt,omitempty"`
+	LeftAt      time.Time     `bson:"leftAt,omitempty" json:"leftAt,omitempty"`
+	PurgeAt     time.Time     `bson:"purgeAt,omitempty" json:"purgeAt,omitempty"`
+	UpdatedAt   time.Time     `bson:"updatedAt" json:"updatedAt"`
+}
+
+// Deleted reports whether the bot has left the group.
+func (g *Group) Deleted() bool {
+	return !g.LeftAt.IsZero()
+}
+
+// Source is the chat the group's assessments are stored under.
+func (g *Group) Source() models.Source {
+	if g.ContextType == models.SourceTypeRoom {
+		return models.Source{Type: models.SourceTypeRoom, RoomID: g.GroupID}
+	}
+	return models.Source{Type: models.SourceTypeGroup, GroupID: g.GroupID}
+}
+
+var groupIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "groupId", Value: 1}}, Options: options.Index().SetUnique(true)},
+	{Keys: bson.D{{Key: "purgeAt", Value: 1}}, Options: options.Index().SetSparse(true)},
+}
+
+type GroupRepository struct {
+	coll *mongo.Collection
+}
+
+func NewGroupRepository(coll *mongo.Collection) *GroupRepository {
+	return &GroupRepository{coll: coll}
+}
+
+// Register records that the bot is in the chat the source belongs to. It
+// reports whether the group had been soft-deleted and is now restored.
+func (r *GroupRepository) Register(ctx context.Context, source models.Source) (bool, error) {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"groupId":     source.ChatID(),
+			"contextType": source.Type,
+			"updatedAt":   now,
+		},
+		"$setOnInsert": bson.M{"joinedAt": now},
+		"$unset":       bson.M{"leftAt": "", "purgeAt": ""},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.Before)
+
+	var before Group
+	err := r.coll.FindOneAndUpdate(ctx, bson.M{"groupId": source.ChatID()}, update, opts).Decode(&before)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return before.Deleted(), nil
+}
+
+// SoftDelete marks the group as left. Its data is purged after grace unless
+// the bot is invited back first. Groups the bot joined before they were
+// registered get a record here, so their data is purged too.
+func (r *GroupRepository) SoftDelete(ctx context.Context, source models.Source, grace time.Duration) error {
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"groupId":     source.ChatID(),
+			"contextType": source.Type,
+			"leftAt":      now,
+			"purgeAt":     now.Add(grace),
+			"updatedAt":   now,
+		},
+	}
+	_, err := r.coll.UpdateOne(ctx, bson.M{"groupId": source.ChatID()}, update, options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+// Expired returns the soft-deleted groups whose grace period ended by now.
+func (r *GroupRepository) Expired(ctx context.Context, now time.Time) ([]Group, error) {
+	cursor, err := r.coll.Find(ctx, bson.M{"purgeAt": bson.M{"$lte": now}})
+	if err != nil {
+		return nil, err
+	}
+	var groups []Group
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Get returns nil when the group is unknown.
+func (r *GroupRepository) Get(ctx context.Context, groupID string) (*Group, error) {
+	var group Group
+	err := r.coll.FindOne(ctx, bson.M{"groupId": groupID}).Decode(&group)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &group, nil
+}
+
+// PurgeExpiredGroups deletes the soft-deleted groups whose grace period
+// ended by now, together with every assessment and history record of their
+// members, the messages kept from the chat and its cached team report. It
+// returns how many groups were purged.
+func (r *Repositories) PurgeExpiredGroups(ctx context.Context, now time.Time) (int, error) {
+	groups, err := r.Groups.Expired(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+	for _, group := range groups {
+		// Removing the record first claims the purge: a group restored in the
+		// meantime no longer matches and is left alone.
+		res, err := r.Groups.coll.DeleteOne(ctx, bson.M{"_id": group.ID, "purgeAt": bson.M{"$lte": now}})
+		if err != nil {
+			return purged, err
+		}
+		if res.DeletedCount == 0 {
+			continue
+		}
+
+		if err := r.deleteChatData(ctx, group.Source()); err != nil {
+			// Put the record back so the next run retries.
+			if _, rerr := r.Groups.coll.InsertOne(ctx, group); rerr != nil {
+				return purged, errors.Join(err, rerr)
+			}
+			return purged, err
+		}
+		if err := r.TeamReports.Delete(ctx, group.GroupID); err != nil {
+			log.Println("⚠️ Failed to delete team report:", group.GroupID, err)
+		}
+		purged++
+	}
+	return purged, nil
+}
+
+func (r *Repositories) deleteChatData(ctx context.Context, source models.Source) error {
+	if err := r.Assessments.DeleteByChat(ctx, source); err != nil {
+		return err
+	}
+	return r.Messages.DeleteByChat(ctx, source)
+}
@@ -0,0 +1,13 @@
+package repository
+
+import "go.mongodb.org/mongo-driver/v2/bson"
+
+// KnowledgeChunk is one embedded passage of the knowledge base, in the
+// layout mongovector writes. Its collection belongs to the active vector
+// generation rather than to Repositories.
+type KnowledgeChunk struct {
+	ID        bson.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Content   string         `bson:"pageContent" json:"content"`
+	Metadata  map[string]any `bson:"metadata,omitempty" json:"metadata,omitempty"`
+	Embedding []float32      `bson:"embedding,omitempty" json:"embedding,omitempty"`
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Member is a LINE user who added the bot as a friend. The record is kept
+// when they block the bot; Active tells the two apart.
+type Member struct {
+	ID           bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	UserID       string        `bson:"userId" json:"userId"`
+	Active       bool          `bson:"active" json:"active"`
+	Unblocked    bool          `bson:"unblocked,omitempty" json:"unblocked,omitempty"`
+	FollowedAt   time.Time     `bson:"followedAt,omitempty" json:"followedAt,omitempty"`
+	UnfollowedAt time.Time     `bson:"unfollowedAt,omitempty" json:"unfollowedAt,omitempty"`
+	UpdatedAt    time.Time     `bson:"updatedAt" json:"updatedAt"`
+}
+
+var memberIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "userId", Value: 1}}, Options: options.Index().SetUnique(true)},
+}
+
+type MemberRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMemberRepository(coll *mongo.Collection) *MemberRepository {
+	return &MemberRepository{coll: coll}
+}
+
+// Follow marks a user as an active friend of the bot.
+func (r *MemberRepository) Follow(ctx context.Context, userID string, unblocked bool) error {
+	now := time.Now()
+	return r.set(ctx, userID, bson.M{
+		"active":     true,
+		"unblocked":  unblocked,
+		"followedAt": now,
+		"updatedAt":  now,
+	})
+}
+
+// Unfollow keeps the user's record but flags that they blocked the bot.
+func (r *MemberRepository) Unfollow(ctx context.Context, userID string) error {
+	now := time.Now()
+	return r.set(ctx, userID, bson.M{
+		"active":       false,
+		"unfollowedAt": now,
+		"updatedAt":    now,
+	})
+}
+
+// Get returns nil when the user never followed the bot.
+func (r *MemberRepository) Get(ctx context.Context, userID string) (*Member, error) {
+	var member Member
+	err := r.coll.FindOne(ctx, bson.M{"userId": userID}).Decode(&member)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *MemberRepository) set(ctx context.Context, userID string, fields bson.M) error {
+	fields["userId"] = userID
+	_, err := r.coll.UpdateOne(ctx, bson.M{"userId": userID}, bson.M{"$set": fields}, options.UpdateOne().SetUpsert(true))
+	return err
+}
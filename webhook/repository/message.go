@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"line-chatbot-golang-langchain/models"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Message is the text of a message the bot acted on, kept so it can be
+// removed again if the sender unsends it.
+type Message struct {
+	ID          bson.ObjectID `bson:"_id,omitempty" json:"-"`
+	MessageID   string        `bson:"messageId" json:"messageId"`
+	ContextType string        `bson:"contextType" json:"contextType"`
+	ContextID   string        `bson:"contextId" json:"contextId"`
+	UserID      string        `bson:"userId,omitempty" json:"userId,omitempty"`
+	Text        string        `bson:"text" json:"text"`
+	CreatedAt   time.Time     `bson:"createdAt" json:"createdAt"`
+}
+
+var messageIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "messageId", Value: 1}}},
+	{Keys: bson.D{{Key: "contextType", Value: 1}, {Key: "contextId", Value: 1}}},
+}
+
+type MessageRepository struct {
+	coll *mongo.Collection
+}
+
+func NewMessageRepository(coll *mongo.Collection) *MessageRepository {
+	return &MessageRepository{coll: coll}
+}
+
+func (r *MessageRepository) Save(ctx context.Context, messageID string, source models.Source, text string) error {
+	msg := Message{
+		MessageID:   messageID,
+		ContextType: source.Type,
+		ContextID:   source.ChatID(),
+		UserID:      source.UserID,
+		Text:        text,
+		CreatedAt:   time.Now(),
+	}
+	_, err := r.coll.ReplaceOne(ctx, bson.M{"messageId": messageID}, msg, options.Replace().SetUpsert(true))
+	return err
+}
+
+// Delete removes every stored copy of a message.
+func (r *MessageRepository) Delete(ctx context.Context, messageID string) error {
+	_, err := r.coll.DeleteMany(ctx, bson.M{"messageId": messageID})
+	return err
+}
+
+// DeleteByChat removes every message kept from a chat.
+func (r *MessageRepository) DeleteByChat(ctx context.Context, source models.Source) error {
+	_, err := r.coll.DeleteMany(ctx, bson.M{"contextType": source.Type, "contextId": source.ChatID()})
+	return err
+}
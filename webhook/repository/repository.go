@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Collection names. Assessments used to be stored next to the group records
+// in "groups"; MigrateLegacyAssessments moves them out.
+const (
+	GroupsCollection      = "groups"
+	MembersCollection     = "users"
+	AssessmentsCollection = "assessments"
+	HistoryCollection     = "assessment_history"
+	MessagesCollection    = "messages"
+	TeamReportsCollection = "team_reports"
+)
+
+// Repositories groups the typed collections of the application database.
+// Every method takes the caller's context, so a cancelled request or a
+// shutdown deadline stops its queries.
+type Repositories struct {
+	Groups      *GroupRepository
+	Members     *MemberRepository
+	Assessments *AssessmentRepository
+	Messages    *MessageRepository
+	TeamReports *TeamReportRepository
+}
+
+func New(db *mongo.Database) *Repositories {
+	return &Repositories{
+		Groups:      NewGroupRepository(db.Collection(GroupsCollection)),
+		Members:     NewMemberRepository(db.Collection(MembersCollection)),
+		Assessments: NewAssessmentRepository(db.Collection(AssessmentsCollection), db.Collection(HistoryCollection)),
+		Messages:    NewMessageRepository(db.Collection(MessagesCollection)),
+		TeamReports: NewTeamReportRepository(db.Collection(TeamReportsCollection)),
+	}
+}
+
+// EnsureIndexes creates the indexes every repository relies on. Creating an
+// index that already exists is a no-op, so it is safe to run at startup.
+func (r *Repositories) EnsureIndexes(ctx context.Context) error {
+	for name, indexes := range map[string]struct {
+		coll   *mongo.Collection
+		models []mongo.IndexModel
+	}{
+		GroupsCollection:      {r.Groups.coll, groupIndexes},
+		MembersCollection:     {r.Members.coll, memberIndexes},
+		AssessmentsCollection: {r.Assessments.coll, assessmentIndexes},
+		HistoryCollection:     {r.Assessments.history, historyIndexes},
+		MessagesCollection:    {r.Messages.coll, messageIndexes},
+		TeamReportsCollection: {r.TeamReports.coll, teamReportIndexes},
+	} {
+		if _, err := indexes.coll.Indexes().CreateMany(ctx, indexes.models); err != nil {
+			return fmt.Errorf("create %s indexes: %w", name, err)
+		}
+	}
+	return nil
+}
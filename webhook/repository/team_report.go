@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"line-chatbot-golang-langchain/coaching"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// TeamReport caches the coaching report of a group or room. Fingerprint
+// identifies the members and results it was written for; a report whose
+// fingerprint no longer matches is stale.
+type TeamReport struct {
+	ID          bson.ObjectID   `bson:"_id,omitempty" json:"-"`
+	GroupID     string          `bson:"groupId" json:"groupId"`
+	Fingerprint string          `bson:"fingerprint" json:"fingerprint"`
+	Report      coaching.Report `bson:"report" json:"report"`
+	CreatedAt   time.Time       `bson:"createdAt" json:"createdAt"`
+}
+
+var teamReportIndexes = []mongo.IndexModel{
+	{Keys: bson.D{{Key: "groupId", Value: 1}}, Options: options.Index().SetUnique(true)},
+}
+
+type TeamReportRepository struct {
+	coll *mongo.Collection
+}
+
+func NewTeamReportRepository(coll *mongo.Collection) *TeamReportRepository {
+	return &TeamReportRepository{coll: coll}
+}
+
+// Get returns nil when no report was cached for the chat.
+func (r *TeamReportRepository) Get(ctx context.Context, chatID string) (*TeamReport, error) {
+	var report TeamReport
+	err := r.coll.FindOne(ctx, bson.M{"groupId": chatID}).Decode(&report)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Put replaces the chat's cached report.
+func (r *TeamReportRepository) Put(ctx context.Context, report *TeamReport) error {
+	report.ID = bson.ObjectID{}
+	if report.CreatedAt.IsZero() {
+		report.CreatedAt = time.Now()
+	}
+	_, err := r.coll.ReplaceOne(ctx, bson.M{"groupId": report.GroupID}, report, options.Replace().SetUpsert(true))
+	return err
+}
+
+func (r *TeamReportRepository) Delete(ctx context.Context, chatID string) error {
+	_, err := r.coll.DeleteOne(ctx, bson.M{"groupId": chatID})
+	return err
+}
@@ -0,0 +1,105 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"line-chatbot-golang-langchain/questionnaire"
+)
+
+// Dimensions in the fixed order used to break ties.
+var Dimensions = []string{"D", "I", "S", "C"}
+
+// BlendThreshold is how close, in percentage points, the second dimension
+// has to be to the first for the style to be reported as a blend like "DI".
+const BlendThreshold = 10.0
+
+type Result struct {
+	// Scores holds the share of each dimension in percent; they sum to 100.
+	Scores    map[string]float64 `json:"scores" bson:"scores"`
+	Primary   string             `json:"primary" bson:"primary"`
+	Secondary string             `json:"secondary,omitempty" bson:"secondary,omitempty"`
+	// Style is the primary letter, followed by the secondary one when the
+	// two are within BlendThreshold of each other.
+	Style string `json:"style" bson:"style"`
+	// Confidence in [0, 1] grows with the lead of the reported style over
+	// the next dimension and with the share of questions answered.
+	Confidence float64 `json:"confidence" bson:"confidence"`
+	Answered   int     `json:"answered" bson:"answered"`
+}
+
+// Score adds up the weights of the chosen options. The same answers always
+// give the same result, whether or not an LLM is available.
+func Score(q questionnaire.Questionnaire, answers []string) (Result, error) {
+	totals := map[string]float64{"D": 0, "I": 0, "S": 0, "C": 0}
+	answered := 0
+
+	for idx, answer := range answers {
+		if idx >= len(q.Questions) {
+			break
+		}
+		id := questionnaire.OptionID(answer)
+		if id == "" {
+			continue
+		}
+		option, ok := q.Questions[idx].Option(id)
+		if !ok {
+			return Result{}, fmt.Errorf("question %d: unknown option %q", idx+1, id)
+		}
+		totals["D"] += option.Weights.D
+		totals["I"] += option.Weights.I
+		totals["S"] += option.Weights.S
+		totals["C"] += option.Weights.C
+		answered++
+	}
+
+	sum := totals["D"] + totals["I"] + totals["S"] + totals["C"]
+	if answered == 0 || sum <= 0 {
+		return Result{}, fmt.Errorf("no scorable answers")
+	}
+
+	scores := make(map[string]float64, len(Dimensions))
+	for _, dim := range Dimensions {
+		scores[dim] = round1(totals[dim] / sum * 100)
+	}
+
+	ranked := Rank(scores)
+	result := Result{
+		Scores:   scores,
+		Primary:  ranked[0],
+		Style:    ranked[0],
+		Answered: answered,
+	}
+
+	lead := scores[ranked[0]] - scores[ranked[1]]
+	top := scores[ranked[0]]
+	if scores[ranked[1]] > 0 && lead <= BlendThreshold {
+		result.Secondary = ranked[1]
+		result.Style = ranked[0] + ranked[1]
+		lead = scores[ranked[1]] - scores[ranked[2]]
+		top = scores[ranked[1]]
+	}
+
+	coverage := float64(answered) / float64(len(q.Questions))
+	result.Confidence = round2(math.Min(1, lead/top) * coverage)
+	return result, nil
+}
+
+// Rank orders the dimensions from highest to lowest score, keeping the
+// D, I, S, C order for ties.
+func Rank(scores map[string]float64) []string {
+	ranked := append([]string(nil), Dimensions...)
+	sort.SliceStable(ranked, func(a, b int) bool {
+		return scores[ranked[a]] > scores[ranked[b]]
+	})
+	return ranked
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
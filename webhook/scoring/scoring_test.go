@@ -0,0 +1,154 @@
+package scoring
+
+import (
+	"reflect"
+	"testing"
+
+	"line-chatbot-golang-langchain/questionnaire"
+)
+
+// split is a one-question bank whose only option weighs D and I as given,
+// to put the two dimensions a chosen distance apart.
+func split(d, i float64) questionnaire.Questionnaire {
+	return questionnaire.Questionnaire{
+		Version: "split",
+		Questions: []questionnaire.Question{{
+			ID:      "q1",
+			Options: []questionnaire.Option{{ID: "A", Weights: questionnaire.Weights{D: d, I: i}}},
+		}},
+	}
+}
+
+func TestScore(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		q          questionnaire.Questionnaire
+		answers    []string
+		scores     map[string]float64
+		style      string
+		secondary  string
+		confidence float64
+		answered   int
+	}{
+		{
+			name:       "all answers the same",
+			q:          questionnaire.V1,
+			answers:    []string{"A", "A", "A", "A", "A"},
+			scores:     map[string]float64{"D": 100, "I": 0, "S": 0, "C": 0},
+			style:      "D",
+			confidence: 1,
+			answered:   5,
+		},
+		{
+			name:       "full labels",
+			q:          questionnaire.V1,
+			answers:    []string{"D. ตรวจสอบรายละเอียดและความถูกต้อง", "D", "D", "D", "C"},
+			scores:     map[string]float64{"D": 0, "I": 0, "S": 20, "C": 80},
+			style:      "C",
+			confidence: 0.75,
+			answered:   5,
+		},
+		{
+			// D and I tie; D comes first in the fixed order.
+			name:       "tie for first",
+			q:          questionnaire.V1,
+			answers:    []string{"A", "B", "A", "B", "C"},
+			scores:     map[string]float64{"D": 40, "I": 40, "S": 20, "C": 0},
+			style:      "DI",
+			secondary:  "I",
+			confidence: 0.5,
+			answered:   5,
+		},
+		{
+			name:       "tie behind the leader",
+			q:          questionnaire.V1,
+			answers:    []string{"C", "C", "C", "A", "D"},
+			scores:     map[string]float64{"D": 20, "I": 0, "S": 60, "C": 20},
+			style:      "S",
+			confidence: 0.67,
+			answered:   5,
+		},
+		{
+			name:      "every dimension equal",
+			q:         questionnaire.V1,
+			answers:   []string{"A", "B", "C", "D", ""},
+			scores:    map[string]float64{"D": 25, "I": 25, "S": 25, "C": 25},
+			style:     "DI",
+			secondary: "I",
+			answered:  4,
+		},
+		{
+			name:       "unanswered questions lower confidence",
+			q:          questionnaire.V1,
+			answers:    []string{"A", "A", "", ""},
+			scores:     map[string]float64{"D": 100, "I": 0, "S": 0, "C": 0},
+			style:      "D",
+			confidence: 0.4,
+			answered:   2,
+		},
+		{
+			name:       "blend at the threshold",
+			q:          split(0.55, 0.45),
+			answers:    []string{"A"},
+			scores:     map[string]float64{"D": 55, "I": 45, "S": 0, "C": 0},
+			style:      "DI",
+			secondary:  "I",
+			confidence: 1,
+			answered:   1,
+		},
+		{
+			name:       "no blend past the threshold",
+			q:          split(0.56, 0.44),
+			answers:    []string{"A"},
+			scores:     map[string]float64{"D": 56, "I": 44, "S": 0, "C": 0},
+			style:      "D",
+			confidence: 0.21,
+			answered:   1,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Score(tt.q, tt.answers)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.Scores, tt.scores) {
+				t.Errorf("Scores = %v, want %v", got.Scores, tt.scores)
+			}
+			if got.Style != tt.style || got.Primary != tt.style[:1] || got.Secondary != tt.secondary {
+				t.Errorf("Style = %q (%q, %q), want %q (secondary %q)", got.Style, got.Primary, got.Secondary, tt.style, tt.secondary)
+			}
+			if got.Confidence != tt.confidence {
+				t.Errorf("Confidence = %v, want %v", got.Confidence, tt.confidence)
+			}
+			if got.Answered != tt.answered {
+				t.Errorf("Answered = %d, want %d", got.Answered, tt.answered)
+			}
+		})
+	}
+}
+
+func TestScoreErrors(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		q       questionnaire.Questionnaire
+		answers []string
+	}{
+		{name: "no answers", q: questionnaire.V1},
+		{name: "only blanks", q: questionnaire.V1, answers: []string{"", " ", "", "", ""}},
+		{name: "unknown option", q: questionnaire.V1, answers: []string{"A", "E"}},
+		{name: "zero weights", q: split(0, 0), answers: []string{"A"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got, err := Score(tt.q, tt.answers); err == nil {
+				t.Errorf("Score = %+v, want error", got)
+			}
+		})
+	}
+}
+
+func TestRankKeepsOrderForTies(t *testing.T) {
+	got := Rank(map[string]float64{"D": 10, "I": 30, "S": 30, "C": 30})
+	if want := []string{"I", "S", "C", "D"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Rank = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,36 @@
+package service
+
+import (
+	"line-chatbot-golang-langchain/repository"
+	"line-chatbot-golang-langchain/utils"
+)
+
+// NewSubmissionService wires the service to LINE, the knowledge base and
+// the given repositories.
+func NewSubmissionService(repos *repository.Repositories, kb *utils.Knowledge) *SubmissionService {
+	return &SubmissionService{
+		VerifyIDToken:  utils.VerifyIDToken,
+		Classify:       kb.ClassifyDisc,
+		SaveAssessment: repos.Assessments.Record,
+	}
+}
+
+// NewHistoryService wires the service to LINE and the given repositories.
+func NewHistoryService(repos *repository.Repositories) *HistoryService {
+	return &HistoryService{
+		VerifyIDToken: utils.VerifyIDToken,
+		LoadHistory:   repos.Assessments.History,
+		LoadCurrent:   repos.Assessments.Get,
+	}
+}
+
+// NewTeamReportService wires the service to the given repositories and the
+// knowledge base.
+func NewTeamReportService(repos *repository.Repositories, kb *utils.Knowledge) *TeamReportService {
+	return &TeamReportService{
+		LoadAssessments: repos.Assessments.ListByChat,
+		Coach:           kb.CoachTeam,
+		LoadCached:      repos.TeamReports.Get,
+		SaveCached:      repos.TeamReports.Put,
+	}
+}
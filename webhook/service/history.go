@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/repository"
+	"line-chatbot-golang-langchain/scoring"
+)
+
+// DefaultHistoryLimit is how many retakes are shown when none is asked for.
+const DefaultHistoryLimit = 10
+
+// MaxHistoryLimit caps the retakes a single request can ask for.
+const MaxHistoryLimit = 50
+
+// bangkok is used for dates shown in chat; a fixed zone avoids depending on
+// tzdata in the container.
+var bangkok = time.FixedZone("ICT", 7*60*60)
+
+// HistoryEntry is one retake and how it differs from the one before it.
+type HistoryEntry struct {
+	ID                   string             `json:"id"`
+	Style                string             `json:"style"`
+	Scores               map[string]float64 `json:"scores"`
+	Confidence           float64            `json:"confidence"`
+	Description          string             `json:"description"`
+	QuestionnaireVersion string             `json:"questionnaireVersion,omitempty"`
+	LLMModel             string             `json:"llmModel,omitempty"`
+	CreatedAt            time.Time          `json:"createdAt"`
+	Current              bool               `json:"current"`
+	// Delta is the change of each score in percentage points since the
+	// previous retake; it is empty for the first one.
+	Delta        map[string]float64 `json:"delta,omitempty"`
+	StyleChanged bool               `json:"styleChanged"`
+}
+
+// History is a user's retakes in one chat, oldest first.
+type History struct {
+	UserID      string         `json:"userId"`
+	ContextType string         `json:"contextType"`
+	Entries     []HistoryEntry `json:"entries"`
+}
+
+// Latest returns the most recent retake, or nil when there is none.
+func (h *History) Latest() *HistoryEntry {
+	if len(h.Entries) == 0 {
+		return nil
+	}
+	return &h.Entries[len(h.Entries)-1]
+}
+
+// HistoryService reads a user's assessment history.
+type HistoryService struct {
+	VerifyIDToken func(ctx context.Context, idToken string) (string, error)
+	LoadHistory   func(ctx context.Context, userID string, source models.Source, limit int) ([]repository.Assessment, error)
+	LoadCurrent   func(ctx context.Context, userID string, source models.Source) (*repository.Assessment, error)
+}
+
+// ForToken returns the history of the user the LIFF ID token was issued to
+// in the chat given by groupID or roomID.
+func (s *HistoryService) ForToken(ctx context.Context, idToken, groupID, roomID string, limit int) (*History, error) {
+	userID, err := s.VerifyIDToken(ctx, idToken)
+	if err != nil {
+		log.Println("🚫 ID token verification failed:", err)
+		return nil, ErrUnauthorized
+	}
+	if userID == "" {
+		return nil, ErrUnauthorized
+	}
+	return s.ForUser(ctx, models.ChatSource(userID, groupID, roomID), limit)
+}
+
+// ForUser returns the history of source.UserID in the source's chat.
+func (s *HistoryService) ForUser(ctx context.Context, source models.Source, limit int) (*History, error) {
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+	limit = min(limit, MaxHistoryLimit)
+
+	records, err := s.LoadHistory(ctx, source.UserID, source, limit)
+	if err != nil {
+		return nil, fmt.Errorf("load history: %w", err)
+	}
+	current, err := s.LoadCurrent(ctx, source.UserID, source)
+	if err != nil {
+		return nil, fmt.Errorf("load current assessment: %w", err)
+	}
+	return BuildHistory(source, records, current), nil
+}
+
+// BuildHistory compares each record with the one before it. A current
+// assessment saved before history was kept is shown as the only entry.
+func BuildHistory(source models.Source, records []repository.Assessment, current *repository.Assessment) *History {
+	if len(records) == 0 && current != nil {
+		records = []repository.Assessment{*current}
+	}
+
+	h := &History{UserID: source.UserID, ContextType: source.Type, Entries: make([]HistoryEntry, 0, len(records))}
+	for i, record := range records {
+		entry := HistoryEntry{
+			ID:                   record.ID.Hex(),
+			Style:                record.Model,
+			Scores:               record.Scores,
+			Confidence:           record.Confidence,
+			Description:          record.Description,
+			QuestionnaireVersion: record.QuestionnaireVersion,
+			LLMModel:             record.LLMModel,
+			CreatedAt:            record.CreatedAt,
+		}
+		if entry.CreatedAt.IsZero() {
+			entry.CreatedAt = record.UpdatedAt
+		}
+		if current != nil {
+			entry.Current = record.ID == current.HistoryID || (current.HistoryID.IsZero() && record.ID == current.ID)
+		}
+		if i > 0 {
+			prev := records[i-1]
+			entry.StyleChanged = prev.Model != record.Model
+			entry.Delta = make(map[string]float64, len(scoring.Dimensions))
+			for _, dim := range scoring.Dimensions {
+				entry.Delta[dim] = record.Scores[dim] - prev.Scores[dim]
+			}
+		}
+		h.Entries = append(h.Entries, entry)
+	}
+	return h
+}
+
+// FormatHistory renders the history for chat, one line per retake, e.g.
+// "2) 20/05/2026 DI · D +5 | I +10 | S -10 | C -5 🔄 จาก D".
+func FormatHistory(h *History) string {
+	lines := make([]string, 0, len(h.Entries)+2)
+	for i, entry := range h.Entries {
+		line := fmt.Sprintf("%d) %s %s · ", i+1, entry.CreatedAt.In(bangkok).Format("02/01/2006"), entry.Style)
+		if i == 0 {
+			line += FormatScores(scoring.Result{Scores: entry.Scores})
+		} else {
+			line += formatDelta(entry.Delta)
+		}
+		if entry.StyleChanged {
+			line += " 🔄 จาก " + h.Entries[i-1].Style
+		}
+		lines = append(lines, line)
+	}
+
+	if len(h.Entries) > 1 {
+		first, last := h.Entries[0], h.Latest()
+		lines = append(lines, "")
+		if first.Style == last.Style {
+			lines = append(lines, fmt.Sprintf("สรุป: ยังเป็นประเภท %s เหมือนเดิม", last.Style))
+		} else {
+			lines = append(lines, fmt.Sprintf("สรุป: เปลี่ยนจาก %s เป็น %s", first.Style, last.Style))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatDelta renders score changes as "D +5 | I 0 | S -10 | C +5".
+func formatDelta(delta map[string]float64) string {
+	parts := make([]string, 0, len(scoring.Dimensions))
+	for _, dim := range scoring.Dimensions {
+		d := delta[dim]
+		switch {
+		case d >= 0.5:
+			parts = append(parts, fmt.Sprintf("%s +%.0f", dim, d))
+		case d <= -0.5:
+			parts = append(parts, fmt.Sprintf("%s %.0f", dim, d))
+		default:
+			parts = append(parts, dim+" 0")
+		}
+	}
+	return strings.Join(parts, " | ")
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/repository"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func assessment(style string, d, i, s, c float64, day int) repository.Assessment {
+	return repository.Assessment{
+		ID:        bson.NewObjectID(),
+		Model:     style,
+		Scores:    map[string]float64{"D": d, "I": i, "S": s, "C": c},
+		CreatedAt: time.Date(2026, 5, day, 3, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestBuildHistory(t *testing.T) {
+	source := models.Source{Type: models.SourceTypeGroup, GroupID: "G1", UserID: "U1"}
+	first := assessment("D", 40, 30, 20, 10, 1)
+	second := assessment("D", 45, 30, 15, 10, 8)
+	third := assessment("DI", 40, 40, 10, 10, 20)
+
+	// Saved before history was kept: no history rows and no HistoryID.
+	legacy := assessment("S", 10, 20, 50, 20, 1)
+	legacy.UpdatedAt, legacy.CreatedAt = legacy.CreatedAt, time.Time{}
+
+	current := third
+	current.ID = bson.NewObjectID()
+	current.HistoryID = third.ID
+
+	for _, tt := range []struct {
+		name      string
+		records   []repository.Assessment
+		current   *repository.Assessment
+		styles    []string
+		isCurrent []bool
+		changed   []bool
+		deltas    []map[string]float64
+	}{
+		{name: "no assessment"},
+		{
+			name:      "legacy current without history",
+			current:   &legacy,
+			styles:    []string{"S"},
+			isCurrent: []bool{true},
+			changed:   []bool{false},
+			deltas:    []map[string]float64{nil},
+		},
+		{
+			name:      "retakes",
+			records:   []repository.Assessment{first, second, third},
+			current:   &current,
+			styles:    []string{"D", "D", "DI"},
+			isCurrent: []bool{false, false, true},
+			changed:   []bool{false, false, true},
+			deltas: []map[string]float64{
+				nil,
+				{"D": 5, "I": 0, "S": -5, "C": 0},
+				{"D": -5, "I": 10, "S": -5, "C": 0},
+			},
+		},
+		{
+			name:      "history without a current assessment",
+			records:   []repository.Assessment{first, second},
+			styles:    []string{"D", "D"},
+			isCurrent: []bool{false, false},
+			changed:   []bool{false, false},
+			deltas:    []map[string]float64{nil, {"D": 5, "I": 0, "S": -5, "C": 0}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := BuildHistory(source, tt.records, tt.current)
+			if h.UserID != "U1" || h.ContextType != models.SourceTypeGroup {
+				t.Errorf("history of %s in %s, want U1 in group", h.UserID, h.ContextType)
+			}
+			if len(h.Entries) != len(tt.styles) {
+				t.Fatalf("%d entries, want %d", len(h.Entries), len(tt.styles))
+			}
+			for i, entry := range h.Entries {
+				if entry.Style != tt.styles[i] {
+					t.Errorf("entry %d style = %s, want %s", i, entry.Style, tt.styles[i])
+				}
+				if entry.Current != tt.isCurrent[i] {
+					t.Errorf("entry %d Current = %t, want %t", i, entry.Current, tt.isCurrent[i])
+				}
+				if entry.StyleChanged != tt.changed[i] {
+					t.Errorf("entry %d StyleChanged = %t, want %t", i, entry.StyleChanged, tt.changed[i])
+				}
+				if !reflect.DeepEqual(entry.Delta, tt.deltas[i]) {
+					t.Errorf("entry %d Delta = %v, want %v", i, entry.Delta, tt.deltas[i])
+				}
+				if entry.CreatedAt.IsZero() {
+					t.Errorf("entry %d has no date", i)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatHistory(t *testing.T) {
+	source := models.Source{Type: models.SourceTypeUser, UserID: "U1"}
+	first := assessment("D", 40, 30, 20, 10, 1)
+	same := assessment("D", 40.2, 30, 19.8, 10, 8)
+	changed := assessment("DI", 35, 40, 15, 10, 20)
+	// 20:00 UTC is already the next day in Bangkok.
+	late := assessment("D", 40, 30, 20, 10, 1)
+	late.CreatedAt = time.Date(2026, 5, 1, 20, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name    string
+		records []repository.Assessment
+		want    string
+	}{
+		{name: "no assessment", want: ""},
+		{
+			name:    "first take shows scores",
+			records: []repository.Assessment{late},
+			want:    "1) 02/05/2026 D · D 40% | I 30% | S 20% | C 10%",
+		},
+		{
+			name:    "small changes round to zero",
+			records: []repository.Assessment{first, same},
+			want: "1) 01/05/2026 D · D 40% | I 30% | S 20% | C 10%\n" +
+				"2) 08/05/2026 D · D 0 | I 0 | S 0 | C 0\n" +
+				"\n" +
+				"สรุป: ยังเป็นประเภท D เหมือนเดิม",
+		},
+		{
+			name:    "style change",
+			records: []repository.Assessment{first, same, changed},
+			want: "1) 01/05/2026 D · D 40% | I 30% | S 20% | C 10%\n" +
+				"2) 08/05/2026 D · D 0 | I 0 | S 0 | C 0\n" +
+				"3) 20/05/2026 DI · D -5 | I +10 | S -5 | C 0 🔄 จาก D\n" +
+				"\n" +
+				"สรุป: เปลี่ยนจาก D เป็น DI",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FormatHistory(BuildHistory(source, tt.records, nil)); got != tt.want {
+				t.Errorf("FormatHistory =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
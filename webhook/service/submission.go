@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"line-chatbot-golang-langchain/classify"
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/questionnaire"
+	"line-chatbot-golang-langchain/repository"
+	"line-chatbot-golang-langchain/scoring"
+)
+
+var (
+	// ErrUnauthorized is returned when the LIFF ID token does not verify.
+	ErrUnauthorized = errors.New("invalid LINE ID token")
+	// ErrInvalidSubmission wraps requests that can never succeed as sent,
+	// e.g. an unknown questionnaire version. Answer-level problems are
+	// reported as *questionnaire.ValidationError instead.
+	ErrInvalidSubmission = errors.New("invalid submission")
+)
+
+// Submission is one answer sheet posted by the LIFF app.
+type Submission struct {
+	IDToken string
+	GroupID string
+	RoomID  string
+	Request models.AnswerRequest
+}
+
+// Result is what one submission produced. Every request gets its own value,
+// so concurrent submissions never share state.
+type Result struct {
+	UserID               string
+	Source               models.Source
+	QuestionnaireVersion string
+	Answers              []string
+	Score                scoring.Result
+	Description          string
+	LLMType              string
+	LLMModel             string
+}
+
+// Document is the result as returned to the LIFF app.
+func (r *Result) Document() map[string]interface{} {
+	return map[string]interface{}{
+		"userId":               r.UserID,
+		"model":                r.Score.Style,
+		"description":          r.Description,
+		"scores":               r.Score.Scores,
+		"confidence":           r.Score.Confidence,
+		"llmType":              r.LLMType,
+		"llmModel":             r.LLMModel,
+		"answers":              r.Answers,
+		"questionnaireVersion": r.QuestionnaireVersion,
+	}
+}
+
+// Assessment is the record saved for the user in the chat.
+func (r *Result) Assessment() *repository.Assessment {
+	a := &repository.Assessment{
+		UserID:               r.UserID,
+		Model:                r.Score.Style,
+		Description:          r.Description,
+		Scores:               r.Score.Scores,
+		Confidence:           r.Score.Confidence,
+		LLMType:              r.LLMType,
+		LLMModel:             r.LLMModel,
+		Answers:              r.Answers,
+		QuestionnaireVersion: r.QuestionnaireVersion,
+	}
+	a.SetChat(r.Source)
+	return a
+}
+
+// SubmissionService scores, explains and stores answer submissions. Its
+// dependencies are plain functions so tests can swap in fakes.
+type SubmissionService struct {
+	// VerifyIDToken returns the LINE user ID the token was issued to.
+	VerifyIDToken  func(ctx context.Context, idToken string) (string, error)
+	Classify       func(ctx context.Context, prompt string) (classify.Result, error)
+	SaveAssessment func(ctx context.Context, a *repository.Assessment) error
+}
+
+// Submit handles one submission end to end and returns its result.
+func (s *SubmissionService) Submit(ctx context.Context, sub Submission) (*Result, error) {
+	// Bad answers are reported before the ID token costs a round trip.
+	q, answers, err := normalize(sub.Request)
+	if err != nil {
+		return nil, err
+	}
+
+	userID, err := s.VerifyIDToken(ctx, sub.IDToken)
+	if err != nil {
+		log.Println("🚫 ID token verification failed:", err)
+		return nil, ErrUnauthorized
+	}
+	if userID == "" {
+		return nil, ErrUnauthorized
+	}
+	log.Println("👤 LINE User ID:", userID)
+
+	result, err := s.evaluate(ctx, q, answers)
+	if err != nil {
+		return nil, err
+	}
+	result.UserID = userID
+	result.Source = models.ChatSource(userID, sub.GroupID, sub.RoomID)
+
+	if err := s.SaveAssessment(ctx, result.Assessment()); err != nil {
+		return nil, fmt.Errorf("save answers: %w", err)
+	}
+	return result, nil
+}
+
+// Evaluate scores and explains an answer sheet without verifying or saving
+// anything, e.g. to try the pipeline from the command line.
+func (s *SubmissionService) Evaluate(ctx context.Context, req models.AnswerRequest) (*Result, error) {
+	q, answers, err := normalize(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.evaluate(ctx, q, answers)
+}
+
+func normalize(req models.AnswerRequest) (questionnaire.Questionnaire, []string, error) {
+	q, err := questionnaire.Resolve(req.Version)
+	if err != nil {
+		return q, nil, fmt.Errorf("%w: %v", ErrInvalidSubmission, err)
+	}
+	answers, err := q.NormalizeAnswers(req.Answers)
+	if err != nil {
+		return q, nil, err
+	}
+	return q, answers, nil
+}
+
+func (s *SubmissionService) evaluate(ctx context.Context, q questionnaire.Questionnaire, answers []string) (*Result, error) {
+	score, err := scoring.Score(q, answers)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSubmission, err)
+	}
+	log.Printf("🧮 DISC scores: %v style=%s confidence=%.2f", score.Scores, score.Style, score.Confidence)
+
+	result := &Result{
+		QuestionnaireVersion: q.Version,
+		Answers:              answers,
+		Score:                score,
+		// The style comes from the scores; the LLM only explains it, and the
+		// result is still saved with a stock description when the LLM is down.
+		Description: FallbackDescription(score),
+	}
+
+	// The prompt is built from the question bank's own labels, never from
+	// text the client sent.
+	labels := strings.Join(q.Labels(answers, questionnaire.DefaultLocale), ", ")
+	prompt := fmt.Sprintf("คำตอบ: %s\nคะแนน DISC ที่คำนวณแล้ว: %s\nประเภทตามคะแนน: %s", labels, FormatScores(score), score.Style)
+
+	classified, err := s.Classify(ctx, prompt)
+	if err != nil {
+		log.Println("⚠️ LLM classification failed, using stock description:", err)
+	} else {
+		log.Printf("📥 LLM classified %s after %d attempts", classified.Model, classified.Attempts)
+		result.LLMType = classified.Model
+		result.LLMModel = classified.LLMModel
+		result.Description = classified.Description
+	}
+	return result, nil
+}
+
+// FormatScores renders percentages as "D 40% | I 20% | S 20% | C 20%".
+func FormatScores(result scoring.Result) string {
+	parts := make([]string, 0, len(scoring.Dimensions))
+	for _, dim := range scoring.Dimensions {
+		parts = append(parts, fmt.Sprintf("%s %.0f%%", dim, result.Scores[dim]))
+	}
+	return strings.Join(parts, " | ")
+}
+
+// FallbackDescription describes a style from the built-in DISC profiles.
+func FallbackDescription(result scoring.Result) string {
+	var parts []string
+	for _, letter := range models.DiscLetters(result.Style) {
+		style := models.DiscStyles[letter]
+		parts = append(parts, fmt.Sprintf("%s (%s): %s", style.Name, style.ThaiName, strings.Join(style.Strengths, ", ")))
+	}
+	return fmt.Sprintf("คะแนน %s\n%s", FormatScores(result), strings.Join(parts, "\n"))
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"line-chatbot-golang-langchain/classify"
+	"line-chatbot-golang-langchain/models"
+)
+
+// The style always comes from the deterministic scores; the LLM's type is
+// recorded next to it but only its description is used.
+func TestEvaluateWeighsScoresOverLLM(t *testing.T) {
+	answers := []string{"A", "A", "A", "B", "C"}
+	for _, tt := range []struct {
+		name        string
+		classified  classify.Result
+		err         error
+		llmType     string
+		description string
+	}{
+		{
+			name:        "LLM agrees",
+			classified:  classify.Result{Model: "D", Description: "llm text", LLMModel: "fake"},
+			llmType:     "D",
+			description: "llm text",
+		},
+		{
+			name:        "LLM disagrees",
+			classified:  classify.Result{Model: "S", Description: "llm text", LLMModel: "fake"},
+			llmType:     "S",
+			description: "llm text",
+		},
+		{
+			name: "LLM down",
+			err:  errors.New("unavailable"),
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := &SubmissionService{
+				Classify: func(ctx context.Context, prompt string) (classify.Result, error) {
+					return tt.classified, tt.err
+				},
+			}
+
+			result, err := svc.Evaluate(context.Background(), models.AnswerRequest{Version: "v1", Answers: answers})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Score.Style != "D" || result.Assessment().Model != "D" {
+				t.Errorf("style = %q, saved %q, want D from the scores", result.Score.Style, result.Assessment().Model)
+			}
+			if result.LLMType != tt.llmType {
+				t.Errorf("LLMType = %q, want %q", result.LLMType, tt.llmType)
+			}
+			want := tt.description
+			if want == "" {
+				want = FallbackDescription(result.Score)
+			}
+			if result.Description != want {
+				t.Errorf("Description = %q, want %q", result.Description, want)
+			}
+			if tt.err != nil && !strings.Contains(result.Description, "D 60%") {
+				t.Errorf("fallback description %q does not show the scores", result.Description)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"line-chatbot-golang-langchain/coaching"
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/repository"
+	"line-chatbot-golang-langchain/team"
+)
+
+// ErrNoResults is returned when nobody in the chat has taken the test yet.
+var ErrNoResults = errors.New("no assessments in this chat")
+
+// TeamReport is the coaching report for a chat with the analysis it was
+// written from.
+type TeamReport struct {
+	Analysis  team.Report     `json:"analysis"`
+	Coaching  coaching.Report `json:"coaching"`
+	Cached    bool            `json:"cached"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// TeamReportService writes LLM coaching reports for groups and rooms. A
+// report is reused until someone in the chat joins, leaves or retakes the
+// test.
+type TeamReportService struct {
+	LoadAssessments func(ctx context.Context, source models.Source) ([]repository.Assessment, error)
+	Coach           func(ctx context.Context, analysis team.Report, members []team.Member) (coaching.Report, error)
+	LoadCached      func(ctx context.Context, chatID string) (*repository.TeamReport, error)
+	SaveCached      func(ctx context.Context, report *repository.TeamReport) error
+}
+
+// ForChat returns the chat's coaching report, from the cache when the
+// members and their results are unchanged.
+func (s *TeamReportService) ForChat(ctx context.Context, source models.Source) (*TeamReport, error) {
+	assessments, err := s.LoadAssessments(ctx, source)
+	if err != nil {
+		return nil, fmt.Errorf("load assessments: %w", err)
+	}
+	if len(assessments) == 0 {
+		return nil, ErrNoResults
+	}
+
+	// Members are numbered in the prompt, so keep their order stable.
+	sort.Slice(assessments, func(i, j int) bool { return assessments[i].UserID < assessments[j].UserID })
+	members := make([]team.Member, 0, len(assessments))
+	for _, a := range assessments {
+		members = append(members, team.Member{UserID: a.UserID, Style: a.Model, Scores: a.Scores})
+	}
+	analysis := team.Analyze(members)
+	fingerprint := Fingerprint(assessments)
+
+	cached, err := s.LoadCached(ctx, source.ChatID())
+	if err != nil {
+		log.Println("⚠️ Failed to load cached team report, regenerating:", err)
+	}
+	if cached != nil && cached.Fingerprint == fingerprint {
+		log.Println("📦 Team report cache hit:", source.ChatID())
+		return &TeamReport{Analysis: analysis, Coaching: cached.Report, Cached: true, CreatedAt: cached.CreatedAt}, nil
+	}
+
+	advice, err := s.Coach(ctx, analysis, members)
+	if err != nil {
+		return nil, fmt.Errorf("coach team: %w", err)
+	}
+	report := &TeamReport{Analysis: analysis, Coaching: advice, CreatedAt: time.Now()}
+
+	err = s.SaveCached(ctx, &repository.TeamReport{
+		GroupID:     source.ChatID(),
+		Fingerprint: fingerprint,
+		Report:      advice,
+		CreatedAt:   report.CreatedAt,
+	})
+	if err != nil {
+		log.Println("⚠️ Failed to cache team report:", err)
+	}
+	return report, nil
+}
+
+// Fingerprint identifies who is in a chat and which result each of them
+// has, together with the coaching prompt version.
+func Fingerprint(assessments []repository.Assessment) string {
+	keys := make([]string, 0, len(assessments))
+	for _, a := range assessments {
+		keys = append(keys, fmt.Sprintf("%s|%s|%s|%d", a.UserID, a.Model, a.HistoryID.Hex(), a.UpdatedAt.UnixMilli()))
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	fmt.Fprintln(h, coaching.PromptVersion)
+	for _, key := range keys {
+		fmt.Fprintln(h, key)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
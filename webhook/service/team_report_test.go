@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"line-chatbot-golang-langchain/coaching"
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/repository"
+	"line-chatbot-golang-langchain/team"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeTeamReports is a TeamReportService over in-memory assessments and
+// cache that counts the coaching calls.
+type fakeTeamReports struct {
+	assessments []repository.Assessment
+	cache       map[string]repository.TeamReport
+	coached     int
+	loadErr     error
+	saveErr     error
+}
+
+func (f *fakeTeamReports) service() *TeamReportService {
+	return &TeamReportService{
+		LoadAssessments: func(ctx context.Context, source models.Source) ([]repository.Assessment, error) {
+			return append([]repository.Assessment(nil), f.assessments...), nil
+		},
+		Coach: func(ctx context.Context, analysis team.Report, members []team.Member) (coaching.Report, error) {
+			f.coached++
+			return coaching.Report{Summary: "advice", LLMModel: "fake"}, nil
+		},
+		LoadCached: func(ctx context.Context, chatID string) (*repository.TeamReport, error) {
+			if f.loadErr != nil {
+				return nil, f.loadErr
+			}
+			if cached, ok := f.cache[chatID]; ok {
+				return &cached, nil
+			}
+			return nil, nil
+		},
+		SaveCached: func(ctx context.Context, report *repository.TeamReport) error {
+			if f.saveErr != nil {
+				return f.saveErr
+			}
+			f.cache[report.GroupID] = *report
+			return nil
+		},
+	}
+}
+
+func teamAssessment(userID, style string) repository.Assessment {
+	return repository.Assessment{
+		UserID:    userID,
+		Model:     style,
+		HistoryID: bson.NewObjectID(),
+		UpdatedAt: time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestTeamReportCache(t *testing.T) {
+	ctx := context.Background()
+	source := models.Source{Type: models.SourceTypeGroup, GroupID: "G1"}
+	f := &fakeTeamReports{
+		assessments: []repository.Assessment{teamAssessment("U2", "S"), teamAssessment("U1", "D"), teamAssessment("U3", "C")},
+		cache:       map[string]repository.TeamReport{},
+	}
+	svc := f.service()
+
+	retake := teamAssessment("U2", "S")
+	retake.UpdatedAt = retake.UpdatedAt.Add(time.Hour)
+
+	for _, tt := range []struct {
+		name   string
+		change func()
+		cached bool
+	}{
+		{name: "first request", cached: false},
+		{name: "nothing changed", cached: true},
+		{name: "member order changed", change: func() { f.assessments[0], f.assessments[2] = f.assessments[2], f.assessments[0] }, cached: true},
+		{name: "member retakes the test", change: func() { f.assessments[2] = retake }, cached: false},
+		{name: "after the retake", cached: true},
+		{name: "member leaves", change: func() { f.assessments = f.assessments[1:] }, cached: false},
+		{name: "member joins", change: func() { f.assessments = append(f.assessments, teamAssessment("U4", "I")) }, cached: false},
+	} {
+		if tt.change != nil {
+			tt.change()
+		}
+		coached := f.coached
+		report, err := svc.ForChat(ctx, source)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if report.Cached != tt.cached {
+			t.Errorf("%s: Cached = %t, want %t", tt.name, report.Cached, tt.cached)
+		}
+		if wantCalls := map[bool]int{true: 0, false: 1}[tt.cached]; f.coached-coached != wantCalls {
+			t.Errorf("%s: coached %d times, want %d", tt.name, f.coached-coached, wantCalls)
+		}
+		if report.Coaching.Summary != "advice" || report.Analysis.Size != len(f.assessments) {
+			t.Errorf("%s: report = %+v", tt.name, report)
+		}
+	}
+}
+
+func TestTeamReportCacheFailures(t *testing.T) {
+	ctx := context.Background()
+	source := models.Source{Type: models.SourceTypeRoom, RoomID: "R1"}
+
+	f := &fakeTeamReports{
+		assessments: []repository.Assessment{teamAssessment("U1", "D")},
+		cache:       map[string]repository.TeamReport{},
+		loadErr:     errors.New("mongo down"),
+		saveErr:     errors.New("mongo down"),
+	}
+	// A broken cache only costs a fresh report.
+	for i := 0; i < 2; i++ {
+		report, err := f.service().ForChat(ctx, source)
+		if err != nil || report.Cached {
+			t.Fatalf("ForChat = %+v, %v, want a fresh report", report, err)
+		}
+	}
+	if f.coached != 2 {
+		t.Errorf("coached %d times, want 2", f.coached)
+	}
+
+	f.assessments = nil
+	if _, err := f.service().ForChat(ctx, source); !errors.Is(err, ErrNoResults) {
+		t.Errorf("ForChat = %v, want ErrNoResults", err)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a, b := teamAssessment("U1", "D"), teamAssessment("U2", "S")
+	base := Fingerprint([]repository.Assessment{a, b})
+
+	retaken := b
+	retaken.HistoryID = bson.NewObjectID()
+	updated := b
+	updated.UpdatedAt = updated.UpdatedAt.Add(time.Second)
+	restyled := b
+	restyled.Model = "SC"
+
+	for name, other := range map[string][]repository.Assessment{
+		"retake":        {a, retaken},
+		"later update":  {a, updated},
+		"other style":   {a, restyled},
+		"member left":   {a},
+		"member joined": {a, b, teamAssessment("U3", "I")},
+	} {
+		if Fingerprint(other) == base {
+			t.Errorf("%s kept the fingerprint", name)
+		}
+	}
+	if Fingerprint([]repository.Assessment{b, a}) != base {
+		t.Error("fingerprint depends on member order")
+	}
+}
@@ -0,0 +1,61 @@
+package team
+
+import "line-chatbot-golang-langchain/scoring"
+
+// compatibility scores how easily two pure styles work together, in [0, 1].
+// The matrix is symmetric: complementary styles score high, styles that
+// compete for the same role or clash on pace score low.
+var compatibility = map[string]map[string]float64{
+	"D": {"D": 0.35, "I": 0.80, "S": 0.55, "C": 0.70},
+	"I": {"D": 0.80, "I": 0.60, "S": 0.80, "C": 0.40},
+	"S": {"D": 0.55, "I": 0.80, "S": 0.70, "C": 0.75},
+	"C": {"D": 0.70, "I": 0.40, "S": 0.75, "C": 0.60},
+}
+
+// pairNotes explain each combination of primary styles, keyed in
+// scoring.Dimensions order ("DI", not "ID").
+var pairNotes = map[string]string{
+	"DD": "ผู้นำทั้งคู่ อาจแย่งกันตัดสินใจ ควรแบ่งขอบเขตความรับผิดชอบให้ชัด",
+	"DI": "เด็ดขาด + สื่อสารเก่ง ผลักดันงานและพาทีมไปด้วยกันได้ดี",
+	"DS": "จังหวะต่างกัน ฝ่ายหนึ่งเร่ง อีกฝ่ายต้องการเวลาปรับตัว",
+	"DC": "ตัดสินใจไว + วิเคราะห์เก่ง ถ้าตกลงเกณฑ์การตัดสินใจกันก่อน",
+	"II": "ไอเดียและพลังเยอะ แต่ต้องมีคนช่วยติดตามงานให้จบ",
+	"IS": "บรรยากาศดี + ทีมเวิร์ค ดูแลความสัมพันธ์ในทีมได้ดี",
+	"IC": "ความยืดหยุ่นกับความละเอียดขัดกัน ควรคุยเรื่องมาตรฐานงานให้ชัด",
+	"SS": "มั่นคงและช่วยเหลือกัน แต่อาจเลี่ยงการตัดสินใจยากๆ",
+	"SC": "มั่นคง + ละเอียด งานมีคุณภาพและสม่ำเสมอ",
+	"CC": "รอบคอบทั้งคู่ แต่อาจวิเคราะห์นานจนงานช้า",
+}
+
+// dominantNotes describe what happens when a style outweighs the others.
+var dominantNotes = map[string]string{
+	"D": "คนนำเยอะ อาจแย่งกันตัดสินใจและกดดันกันเอง",
+	"I": "คุยสนุกและมีไอเดียมาก แต่อาจขาดการลงรายละเอียดและปิดงาน",
+	"S": "ทีมราบรื่น แต่อาจต้านการเปลี่ยนแปลงและไม่กล้าแย้งกัน",
+	"C": "งานละเอียด แต่อาจตัดสินใจช้าและเข้มงวดกันเกินไป",
+}
+
+// missingNotes describe what a team without a style tends to lack.
+var missingNotes = map[string]string{
+	"D": "ขาดคนตัดสินใจและผลักดันงานให้ถึงเป้า",
+	"I": "ขาดคนสร้างพลังบวกและสื่อสารกับคนนอกทีม",
+	"S": "ขาดคนประสานและดูแลความรู้สึกของทีม",
+	"C": "ขาดคนตรวจรายละเอียดและคุมคุณภาพงาน",
+}
+
+// pairKey orders two letters the way scoring.Dimensions does.
+func pairKey(a, b string) string {
+	if dimensionIndex(a) > dimensionIndex(b) {
+		a, b = b, a
+	}
+	return a + b
+}
+
+func dimensionIndex(letter string) int {
+	for i, dim := range scoring.Dimensions {
+		if dim == letter {
+			return i
+		}
+	}
+	return len(scoring.Dimensions)
+}
@@ -0,0 +1,316 @@
+package team
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/scoring"
+)
+
+const (
+	// DominantShare is the team-average share, in percent, above which a
+	// style is reported as dominant. An even team has 25% of each.
+	DominantShare = 35.0
+	// GoodPair and FrictionPair split pair compatibility into levels.
+	GoodPair     = 0.65
+	FrictionPair = 0.5
+)
+
+// Pair levels.
+const (
+	LevelGood     = "good"
+	LevelFair     = "fair"
+	LevelFriction = "friction"
+)
+
+// Friction kinds: between two members, or across the team.
+const (
+	FrictionPairKind = "pair"
+	FrictionTeamKind = "team"
+)
+
+// Member is one person's result as input to Analyze. Scores may be empty
+// for results saved before scoring existed; the style is used instead.
+type Member struct {
+	UserID string
+	Style  string
+	Scores map[string]float64
+}
+
+// Report is the team analysis. It holds data only, so chat replies and
+// APIs render it their own way.
+type Report struct {
+	Size int `json:"size"`
+	// Unscored lists members with neither a style nor scores.
+	Unscored []string `json:"unscored,omitempty"`
+	// Average is the team's mean share of each style, in percent.
+	Average map[string]float64 `json:"average"`
+	// Primary counts members by their main style; Presence counts every
+	// member whose style includes the letter, so "DI" counts for D and I.
+	Primary  map[string]int `json:"primary"`
+	Presence map[string]int `json:"presence"`
+	// Balance is 1 when the four styles are evenly represented and falls
+	// towards 0 as one style takes over.
+	Balance   float64    `json:"balance"`
+	Dominant  []Style    `json:"dominant"`
+	Missing   []Style    `json:"missing"`
+	Pairs     []Pair     `json:"pairs"`
+	Frictions []Friction `json:"frictions"`
+}
+
+// Style is a dominant or missing style with what it means for the team.
+type Style struct {
+	Style string  `json:"style"`
+	Share float64 `json:"share"`
+	Note  string  `json:"note"`
+}
+
+// Pair is how well two members are likely to work together.
+type Pair struct {
+	A      string  `json:"a"`
+	B      string  `json:"b"`
+	StyleA string  `json:"styleA"`
+	StyleB string  `json:"styleB"`
+	Score  float64 `json:"score"`
+	Level  string  `json:"level"`
+	Note   string  `json:"note"`
+}
+
+// Friction is a likely source of conflict: a low-scoring pair, or a style
+// several members share when it dominates the team.
+type Friction struct {
+	Kind    string   `json:"kind"`
+	Style   string   `json:"style"`
+	Members []string `json:"members"`
+	Note    string   `json:"note"`
+}
+
+type profile struct {
+	Member
+	weights map[string]float64
+	letters []string
+}
+
+// Analyze computes the team report. Members are taken in the given order,
+// which is also the order of the pairs before they are ranked.
+func Analyze(members []Member) Report {
+	report := Report{
+		Average:   zeroScores(),
+		Primary:   map[string]int{},
+		Presence:  map[string]int{},
+		Dominant:  []Style{},
+		Missing:   []Style{},
+		Pairs:     []Pair{},
+		Frictions: []Friction{},
+	}
+	for _, dim := range scoring.Dimensions {
+		report.Primary[dim] = 0
+		report.Presence[dim] = 0
+	}
+
+	profiles := make([]profile, 0, len(members))
+	for _, m := range members {
+		p, ok := newProfile(m)
+		if !ok {
+			report.Unscored = append(report.Unscored, m.UserID)
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	report.Size = len(profiles)
+	if report.Size == 0 {
+		return report
+	}
+
+	for _, p := range profiles {
+		for _, dim := range scoring.Dimensions {
+			report.Average[dim] += p.weights[dim] * 100 / float64(report.Size)
+		}
+		report.Primary[p.letters[0]]++
+		for _, letter := range p.letters {
+			report.Presence[letter]++
+		}
+	}
+	for _, dim := range scoring.Dimensions {
+		report.Average[dim] = round(report.Average[dim], 1)
+	}
+	report.Balance = balance(report.Average)
+
+	for _, dim := range scoring.Dimensions {
+		share := report.Average[dim]
+		if share >= DominantShare {
+			report.Dominant = append(report.Dominant, Style{Style: dim, Share: share, Note: dominantNotes[dim]})
+		}
+		if report.Presence[dim] == 0 {
+			report.Missing = append(report.Missing, Style{Style: dim, Share: share, Note: missingNotes[dim]})
+		}
+	}
+	sort.SliceStable(report.Dominant, func(i, j int) bool {
+		return report.Dominant[i].Share > report.Dominant[j].Share
+	})
+
+	for i := 0; i < len(profiles); i++ {
+		for j := i + 1; j < len(profiles); j++ {
+			report.Pairs = append(report.Pairs, pair(profiles[i], profiles[j]))
+		}
+	}
+	sort.SliceStable(report.Pairs, func(i, j int) bool {
+		return report.Pairs[i].Score > report.Pairs[j].Score
+	})
+
+	report.Frictions = frictions(report, profiles)
+	return report
+}
+
+// newProfile normalizes a member's scores to fractions that sum to 1,
+// falling back to an even split over the letters of their style.
+func newProfile(m Member) (profile, bool) {
+	p := profile{Member: m, weights: zeroScores(), letters: models.DiscLetters(m.Style)}
+
+	sum := 0.0
+	for _, dim := range scoring.Dimensions {
+		sum += math.Max(m.Scores[dim], 0)
+	}
+	switch {
+	case sum > 0:
+		for _, dim := range scoring.Dimensions {
+			p.weights[dim] = math.Max(m.Scores[dim], 0) / sum
+		}
+	case len(p.letters) > 0:
+		for _, letter := range p.letters {
+			p.weights[letter] = 1 / float64(len(p.letters))
+		}
+	default:
+		return p, false
+	}
+
+	if len(p.letters) == 0 {
+		top := scoring.Dimensions[0]
+		for _, dim := range scoring.Dimensions {
+			if p.weights[dim] > p.weights[top] {
+				top = dim
+			}
+		}
+		p.letters = []string{top}
+	}
+	return p, true
+}
+
+// pair weighs the compatibility of every style combination by how much of
+// each style the two members have.
+func pair(a, b profile) Pair {
+	score := 0.0
+	for _, x := range scoring.Dimensions {
+		for _, y := range scoring.Dimensions {
+			score += a.weights[x] * b.weights[y] * compatibility[x][y]
+		}
+	}
+
+	p := Pair{
+		A:      a.UserID,
+		B:      b.UserID,
+		StyleA: styleLabel(a),
+		StyleB: styleLabel(b),
+		Score:  round(score, 2),
+	}
+	switch {
+	case p.Score >= GoodPair:
+		p.Level = LevelGood
+	case p.Score < FrictionPair:
+		p.Level = LevelFriction
+	default:
+		p.Level = LevelFair
+	}
+	p.Note = pairNotes[noteKey(a.letters, b.letters, p.Level)]
+	return p
+}
+
+// noteKey picks the letter combination that explains the level: the best
+// matching one for a good pair, the worst for friction, and the primary
+// styles otherwise. A "DI" and an "S" get along thanks to I and S.
+func noteKey(a, b []string, level string) string {
+	key := pairKey(a[0], b[0])
+	if level == LevelFair {
+		return key
+	}
+	best := compatibility[a[0]][b[0]]
+	for _, x := range a {
+		for _, y := range b {
+			c := compatibility[x][y]
+			if (level == LevelGood && c > best) || (level == LevelFriction && c < best) {
+				best, key = c, pairKey(x, y)
+			}
+		}
+	}
+	return key
+}
+
+// frictions lists friction pairs, worst first, then dominant styles shared
+// by more than one member.
+func frictions(report Report, profiles []profile) []Friction {
+	out := []Friction{}
+	for i := len(report.Pairs) - 1; i >= 0; i-- {
+		p := report.Pairs[i]
+		if p.Level != LevelFriction {
+			break
+		}
+		out = append(out, Friction{
+			Kind:    FrictionPairKind,
+			Style:   p.StyleA + "-" + p.StyleB,
+			Members: []string{p.A, p.B},
+			Note:    p.Note,
+		})
+	}
+
+	for _, dominant := range report.Dominant {
+		var members []string
+		for _, p := range profiles {
+			if p.letters[0] == dominant.Style {
+				members = append(members, p.UserID)
+			}
+		}
+		if len(members) < 2 {
+			continue
+		}
+		out = append(out, Friction{
+			Kind:    FrictionTeamKind,
+			Style:   dominant.Style,
+			Members: members,
+			Note:    dominant.Note,
+		})
+	}
+	return out
+}
+
+// balance compares the average shares with an even split: 1 minus their
+// distance from it, scaled so a team of one pure style scores 0.
+func balance(average map[string]float64) float64 {
+	even := 100 / float64(len(scoring.Dimensions))
+	distance := 0.0
+	for _, dim := range scoring.Dimensions {
+		distance += math.Abs(average[dim] - even)
+	}
+	worst := 2 * (100 - even)
+	return round(math.Max(0, 1-distance/worst), 2)
+}
+
+// styleLabel is the member's style as letters, e.g. "DI" for a legacy
+// "DI (Dominance/Influence)" label.
+func styleLabel(p profile) string {
+	return strings.Join(p.letters, "")
+}
+
+func zeroScores() map[string]float64 {
+	scores := make(map[string]float64, len(scoring.Dimensions))
+	for _, dim := range scoring.Dimensions {
+		scores[dim] = 0
+	}
+	return scores
+}
+
+func round(v float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,209 @@
+package team
+
+import (
+	"reflect"
+	"testing"
+)
+
+func scores(d, i, s, c float64) map[string]float64 {
+	return map[string]float64{"D": d, "I": i, "S": s, "C": c}
+}
+
+func styles(list []Style) []string {
+	out := []string{}
+	for _, s := range list {
+		out = append(out, s.Style)
+	}
+	return out
+}
+
+func TestAnalyzeWithoutScoredMembers(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		members  []Member
+		unscored []string
+	}{
+		{name: "empty team"},
+		{
+			name:     "all unscored",
+			members:  []Member{{UserID: "U1"}, {UserID: "U2", Style: "Xavier"}, {UserID: "U3", Scores: scores(0, 0, 0, 0)}},
+			unscored: []string{"U1", "U2", "U3"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Analyze(tt.members)
+			if report.Size != 0 || report.Balance != 0 {
+				t.Errorf("size %d balance %v, want 0 and 0", report.Size, report.Balance)
+			}
+			if !reflect.DeepEqual(report.Unscored, tt.unscored) {
+				t.Errorf("Unscored = %v, want %v", report.Unscored, tt.unscored)
+			}
+			// Empty lists, not nil, so the JSON has [] rather than null.
+			if report.Dominant == nil || report.Missing == nil || report.Pairs == nil || report.Frictions == nil {
+				t.Error("report has nil lists")
+			}
+			if report.Primary["D"] != 0 || report.Average["D"] != 0 {
+				t.Errorf("Primary %v Average %v, want zeros", report.Primary, report.Average)
+			}
+		})
+	}
+}
+
+func TestAnalyzeLegacyStyle(t *testing.T) {
+	report := Analyze([]Member{
+		{UserID: "U1", Style: "DI (Dominance/Influence)"},
+		{UserID: "U2", Style: "S", Scores: scores(10, 10, 70, 10)},
+	})
+	if report.Size != 2 || report.Unscored != nil {
+		t.Fatalf("size %d unscored %v, want both members scored", report.Size, report.Unscored)
+	}
+	if want := scores(30, 30, 35, 5); !reflect.DeepEqual(report.Average, want) {
+		t.Errorf("Average = %v, want %v", report.Average, want)
+	}
+	if report.Primary["D"] != 1 || report.Presence["D"] != 1 || report.Presence["I"] != 1 {
+		t.Errorf("Primary %v Presence %v, want DI to count as primary D and present D and I", report.Primary, report.Presence)
+	}
+	if got := report.Pairs[0]; got.StyleA != "DI" || got.StyleB != "S" {
+		t.Errorf("pair styles = %s-%s, want DI-S", got.StyleA, got.StyleB)
+	}
+}
+
+func TestAnalyzeBalance(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		members []Member
+		want    float64
+	}{
+		{name: "single pure style", members: []Member{{UserID: "U1", Scores: scores(100, 0, 0, 0)}}, want: 0},
+		{name: "team of one style", members: []Member{{UserID: "U1", Style: "C"}, {UserID: "U2", Style: "C"}}, want: 0},
+		{
+			name: "one of each style",
+			members: []Member{
+				{UserID: "U1", Style: "D"}, {UserID: "U2", Style: "I"},
+				{UserID: "U3", Style: "S"}, {UserID: "U4", Style: "C"},
+			},
+			want: 1,
+		},
+		{name: "even scores", members: []Member{{UserID: "U1", Scores: scores(25, 25, 25, 25)}}, want: 1},
+		{name: "half and half", members: []Member{{UserID: "U1", Style: "D"}, {UserID: "U2", Style: "S"}}, want: 0.33},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Analyze(tt.members).Balance; got != tt.want {
+				t.Errorf("Balance = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzeDominantAndMissing(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		member   Member
+		dominant []string
+		missing  []string
+	}{
+		{
+			name:     "share at the threshold",
+			member:   Member{Style: "DI", Scores: scores(35, 25, 20, 20)},
+			dominant: []string{"D"},
+			missing:  []string{"S", "C"},
+		},
+		{
+			name:     "share just below",
+			member:   Member{Style: "DI", Scores: scores(34.9, 25.1, 20, 20)},
+			dominant: []string{},
+			missing:  []string{"S", "C"},
+		},
+		{
+			name:     "largest share first",
+			member:   Member{Style: "SC", Scores: scores(0, 0, 40, 60)},
+			dominant: []string{"C", "S"},
+			missing:  []string{"D", "I"},
+		},
+		{
+			// Without a style only the top score counts as present.
+			name:     "scores without a style",
+			member:   Member{Scores: scores(20, 30, 30, 20)},
+			dominant: []string{},
+			missing:  []string{"D", "S", "C"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.member.UserID = "U1"
+			report := Analyze([]Member{tt.member})
+			if got := styles(report.Dominant); !reflect.DeepEqual(got, tt.dominant) {
+				t.Errorf("Dominant = %v, want %v", got, tt.dominant)
+			}
+			if got := styles(report.Missing); !reflect.DeepEqual(got, tt.missing) {
+				t.Errorf("Missing = %v, want %v", got, tt.missing)
+			}
+		})
+	}
+}
+
+func TestPairLevels(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		b     map[string]float64
+		score float64
+		level string
+	}{
+		{name: "complementary", b: scores(0, 100, 0, 0), score: 0.8, level: LevelGood},
+		{name: "at the good threshold", b: scores(0, 0, 1, 2), score: GoodPair, level: LevelGood},
+		{name: "just below good", b: scores(0, 0, 2, 3), score: 0.64, level: LevelFair},
+		{name: "at the friction threshold", b: scores(1, 0, 3, 0), score: FrictionPair, level: LevelFair},
+		{name: "just below friction", b: scores(3, 0, 7, 0), score: 0.49, level: LevelFriction},
+		{name: "same style", b: scores(100, 0, 0, 0), score: 0.35, level: LevelFriction},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Analyze([]Member{
+				{UserID: "A", Scores: scores(100, 0, 0, 0)},
+				{UserID: "B", Scores: tt.b},
+			})
+			got := report.Pairs[0]
+			if got.Score != tt.score || got.Level != tt.level {
+				t.Errorf("pair = %v %s, want %v %s", got.Score, got.Level, tt.score, tt.level)
+			}
+			if got.Note == "" {
+				t.Error("pair has no note")
+			}
+		})
+	}
+}
+
+// Friction pairs come worst first, followed by dominant styles shared by
+// more than one member.
+func TestAnalyzeFrictionOrder(t *testing.T) {
+	report := Analyze([]Member{
+		{UserID: "I1", Style: "I"},
+		{UserID: "D1", Style: "D"},
+		{UserID: "C1", Style: "C"},
+		{UserID: "D2", Style: "D"},
+	})
+
+	type friction struct {
+		kind, style string
+		members     []string
+	}
+	var got []friction
+	for _, f := range report.Frictions {
+		got = append(got, friction{f.Kind, f.Style, f.Members})
+		if f.Note == "" {
+			t.Errorf("%s friction %s has no note", f.Kind, f.Style)
+		}
+	}
+	want := []friction{
+		{FrictionPairKind, "D-D", []string{"D1", "D2"}},
+		{FrictionPairKind, "I-C", []string{"I1", "C1"}},
+		{FrictionTeamKind, "D", []string{"D1", "D2"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Frictions = %v, want %v", got, want)
+	}
+
+	for i := 1; i < len(report.Pairs); i++ {
+		if report.Pairs[i].Score > report.Pairs[i-1].Score {
+			t.Fatalf("pairs not ranked: %v", report.Pairs)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+
+	"line-chatbot-golang-langchain/embed"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// NewEmbedder creates the configured embedder once at startup, behind a
+// batcher and the embedding cache:
+//
+//	EMBEDDING_BATCH_SIZE  texts per request (default 32)
+//	EMBEDDING_CACHE       mongo (LRU + MongoDB, default), memory or off
+//	EMBEDDING_CACHE_SIZE  vectors kept in the in-memory LRU (default 2000)
+//
+// db holds the mongo cache; it may be nil when the cache is memory or off.
+func NewEmbedder(ctx context.Context, db *mongo.Database) (embed.Embedder, error) {
+	base, err := embed.FromEnv(ctx)
+	if err != nil {
+		log.Println("❌ ไม่สามารถสร้าง embedder ได้:", err)
+		return nil, err
+	}
+
+	e := embed.Batched(base, GetEnvInt("EMBEDDING_BATCH_SIZE", 32))
+
+	switch mode := os.Getenv("EMBEDDING_CACHE"); mode {
+	case "off":
+	case "memory":
+		e = embed.NewCached(e, embed.NewLRU(GetEnvInt("EMBEDDING_CACHE_SIZE", 2000)))
+	default:
+		if mode != "" && mode != "mongo" {
+			log.Printf("⚠️ Unknown EMBEDDING_CACHE=%q, using mongo", mode)
+		}
+		if db == nil {
+			return nil, errors.New("EMBEDDING_CACHE=mongo needs MongoDB; use memory or off")
+		}
+		e = embed.NewCached(e,
+			embed.NewLRU(GetEnvInt("EMBEDDING_CACHE_SIZE", 2000)),
+			embed.NewMongoCache(db.Collection("embedding_cache")))
+	}
+
+	log.Printf("🧬 Embedder ready, model: %s (%d dimensions)", base.Model(), base.Dimensions())
+	return e, nil
+}
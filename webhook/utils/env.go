@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// GetEnvInt reads an integer setting, falling back to def when it is unset
+// or not a number.
+func GetEnvInt(key string, def int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("⚠️ Invalid %s=%q, using default %d", key, value, def)
+		return def
+	}
+	return n
+}
+
+// GetEnvPositiveInt is GetEnvInt for settings that must be at least 1,
+// such as a worker count or queue size.
+func GetEnvPositiveInt(key string, def int) int {
+	n := GetEnvInt(key, def)
+	if n < 1 {
+		log.Printf("⚠️ %s must be at least 1, got %d; using default %d", key, n, def)
+		return def
+	}
+	return n
+}
+
+// GetEnvDuration reads a duration setting such as "30s" or "72h".
+func GetEnvDuration(key string, def time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return def
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("⚠️ Invalid %s=%q, using default %s", key, value, def)
+		return def
+	}
+	return d
+}
+
+// GetEnvPositiveDuration is GetEnvDuration for settings where zero or a
+// negative value makes no sense, such as a ticker interval.
+func GetEnvPositiveDuration(key string, def time.Duration) time.Duration {
+	d := GetEnvDuration(key, def)
+	if d <= 0 {
+		log.Printf("⚠️ %s must be positive, got %s; using default %s", key, d, def)
+		return def
+	}
+	return d
+}
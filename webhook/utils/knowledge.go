@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"context"
+	"log"
+	"os"
+
+	"line-chatbot-golang-langchain/ingest"
+	"line-chatbot-golang-langchain/vectorstore"
+)
+
+// KnowledgeManifest is the manifest of knowledge-base sources, set with
+// KNOWLEDGE_MANIFEST.
+func KnowledgeManifest() string {
+	if path := os.Getenv("KNOWLEDGE_MANIFEST"); path != "" {
+		return path
+	}
+	return "knowledge/manifest.json"
+}
+
+// KnowledgeLedger returns where ingested sources are tracked: a MongoDB
+// collection next to the Atlas store, or a file next to a saved memory store.
+func (kb *Knowledge) KnowledgeLedger() (ingest.Ledger, error) {
+	if _, ok := kb.Store.(*vectorstore.MemoryStore); ok {
+		path := os.Getenv("VECTOR_STORE_FILE")
+		if path != "" {
+			path += ".sources.json"
+		}
+		return ingest.LoadFileLedger(path)
+	}
+	if kb.Generation == nil {
+		return nil, errNotMongo
+	}
+	return ingest.NewMongoLedger(kb.DB.Collection(kb.Generation.Ledger)), nil
+}
+
+// IngestOptions tunes IngestKnowledge. The zero value ingests the
+// configured manifest, skipping unchanged sources.
+type IngestOptions struct {
+	// Manifest overrides KNOWLEDGE_MANIFEST.
+	Manifest string
+	// Force embeds every source again; Prune drops sources that left the
+	// manifest.
+	Force bool
+	Prune bool
+	// OnSource reports progress; by default it is logged.
+	OnSource func(ingest.SourceReport)
+}
+
+// IngestKnowledge brings the vector store in line with the manifest: new
+// and changed sources are (re)embedded, unchanged ones are skipped.
+func (kb *Knowledge) IngestKnowledge(ctx context.Context, opts IngestOptions) (*ingest.Report, error) {
+	path := opts.Manifest
+	if path == "" {
+		path = KnowledgeManifest()
+	}
+	manifest, err := ingest.LoadManifest(path)
+	if err != nil {
+		log.Println("❌ Failed to load knowledge manifest:", err)
+		return nil, err
+	}
+	ledger, err := kb.KnowledgeLedger()
+	if err != nil {
+		return nil, err
+	}
+
+	onSource := opts.OnSource
+	if onSource == nil {
+		onSource = func(sr ingest.SourceReport) {
+			log.Printf("📄 %s: %s (%d chunks, %d duplicates skipped, %d old chunks removed)", sr.ID, sr.Status, sr.Chunks, sr.Skipped, sr.Deleted)
+		}
+	}
+	pipeline := &ingest.Pipeline{
+		Store:    kb.Store,
+		Ledger:   ledger,
+		Force:    opts.Force,
+		Prune:    opts.Prune,
+		OnSource: onSource,
+	}
+	report, err := pipeline.Run(ctx, manifest)
+
+	// Keep the sources that finished even when the run stopped early.
+	if saveErr := kb.saveKnowledge(ledger); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return report, err
+}
+
+// saveKnowledge writes a file-backed store and then its ledger. In that
+// order a failure in between leaves the ledger behind the store, which
+// only costs re-ingesting a source, never a source the store lacks.
+func (kb *Knowledge) saveKnowledge(ledger ingest.Ledger) error {
+	if err := kb.SaveVectorStore(); err != nil {
+		log.Println("❌ Failed to save vector store:", err)
+		return err
+	}
+	if fl, ok := ledger.(*ingest.FileLedger); ok {
+		if err := fl.Save(); err != nil {
+			log.Println("❌ Failed to save ingestion ledger:", err)
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertVectors ingests the knowledge base and makes sure the Atlas vector
+// index fits the embedder.
+func (kb *Knowledge) InsertVectors(ctx context.Context) {
+	report, err := kb.IngestKnowledge(ctx, IngestOptions{})
+	if err != nil {
+		log.Println("❌ Knowledge ingestion failed:", err)
+		return
+	}
+	log.Printf("✅ Ingested %d sources, %d failed", len(report.Sources), report.Failed())
+
+	// สร้าง vector index ด้วย Go SDK
+	if kb.Generation == nil {
+		return
+	}
+	if _, err := kb.EnsureVectorIndex(ctx); err != nil {
+		log.Println("❌ Failed to create Atlas vector index:", err)
+	}
+}
@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"line-chatbot-golang-langchain/embed"
+	"line-chatbot-golang-langchain/ingest"
+	"line-chatbot-golang-langchain/vectorstore"
+)
+
+// flakyEmbedder fails for texts containing failOn, when set.
+type flakyEmbedder struct {
+	failOn string
+}
+
+func (e *flakyEmbedder) EmbedDocuments(_ context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if e.failOn != "" && strings.Contains(text, e.failOn) {
+			return nil, errors.New("embedding service unavailable")
+		}
+		vectors[i] = []float32{float32(len(text)), 1}
+	}
+	return vectors, nil
+}
+
+func (e *flakyEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	vectors, err := e.EmbedDocuments(ctx, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+func (e *flakyEmbedder) Model() string                { return "flaky" }
+func (e *flakyEmbedder) Dimensions() int              { return 2 }
+func (e *flakyEmbedder) Similarity() embed.Similarity { return embed.Cosine }
+
+// knowledgeDir writes a manifest of two text sources and points
+// VECTOR_STORE_FILE into the same directory.
+func knowledgeDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"manifest.json": `{"sources": [
+			{"id": "alpha", "path": "alpha.txt", "type": "text"},
+			{"id": "beta", "path": "beta.txt", "type": "text"}
+		]}`,
+		"alpha.txt": "alpha: people who lead from the front",
+		"beta.txt":  "beta: people who keep the team steady",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("VECTOR_STORE_FILE", filepath.Join(dir, "store.json"))
+	return dir
+}
+
+// restart reloads the store from its file, as a new process would.
+func restart(t *testing.T, e embed.Embedder) *Knowledge {
+	t.Helper()
+	store, err := vectorstore.LoadMemoryStore(os.Getenv("VECTOR_STORE_FILE"), e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Knowledge{Embedder: e, Store: store}
+}
+
+func statuses(report *ingest.Report) map[string]string {
+	out := map[string]string{}
+	if report == nil {
+		return out
+	}
+	for _, sr := range report.Sources {
+		out[sr.ID] = sr.Status
+	}
+	return out
+}
+
+func TestIngestKnowledgeRetriesFailedSource(t *testing.T) {
+	ctx := context.Background()
+	dir := knowledgeDir(t)
+	opts := IngestOptions{Manifest: filepath.Join(dir, "manifest.json"), OnSource: func(ingest.SourceReport) {}}
+
+	kb := restart(t, &flakyEmbedder{failOn: "beta"})
+	report, err := kb.IngestKnowledge(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := statuses(report); got["alpha"] != ingest.StatusAdded || got["beta"] != ingest.StatusFailed {
+		t.Fatalf("first run = %v, want alpha added and beta failed", got)
+	}
+
+	kb = restart(t, &flakyEmbedder{})
+	if n, _ := kb.Store.Count(ctx); n != 1 {
+		t.Fatalf("saved store holds %d chunks, want alpha's 1", n)
+	}
+	report, err = kb.IngestKnowledge(ctx, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := statuses(report); got["alpha"] != ingest.StatusUnchanged || got["beta"] != ingest.StatusAdded {
+		t.Errorf("second run = %v, want alpha unchanged and beta added", got)
+	}
+	if n, _ := restart(t, &flakyEmbedder{}).Store.Count(ctx); n != 2 {
+		t.Errorf("saved store holds %d chunks, want 2", n)
+	}
+}
+
+// A run that stops part way still saves the sources it finished, and the
+// ledger never claims a source the saved store does not hold.
+func TestIngestKnowledgeSavesInterruptedRun(t *testing.T) {
+	dir := knowledgeDir(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	opts := IngestOptions{
+		Manifest: filepath.Join(dir, "manifest.json"),
+		OnSource: func(sr ingest.SourceReport) {
+			if sr.ID == "alpha" {
+				cancel()
+			}
+		},
+	}
+
+	kb := restart(t, &flakyEmbedder{})
+	if _, err := kb.IngestKnowledge(ctx, opts); !errors.Is(err, context.Canceled) {
+		t.Fatalf("IngestKnowledge = %v, want context.Canceled", err)
+	}
+
+	kb = restart(t, &flakyEmbedder{})
+	if n, _ := kb.Store.Count(context.Background()); n != 1 {
+		t.Fatalf("saved store holds %d chunks, want alpha's 1", n)
+	}
+	opts.OnSource = func(ingest.SourceReport) {}
+	report, err := kb.IngestKnowledge(context.Background(), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := statuses(report); got["alpha"] != ingest.StatusUnchanged || got["beta"] != ingest.StatusAdded {
+		t.Errorf("next run = %v, want alpha unchanged and beta added", got)
+	}
+}
@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"net/url"
+	"os"
+
+	"line-chatbot-golang-langchain/models"
+)
+
+// LiffURL returns the DISC LIFF link for a chat, carrying the group or room
+// ID so the submission is stored against the chat it was started from.
+func LiffURL(source models.Source) string {
+	base := os.Getenv("LINE_LIFF_DISC")
+	query := url.Values{}
+	switch source.Type {
+	case models.SourceTypeGroup:
+		query.Set("groupId", source.GroupID)
+	case models.SourceTypeRoom:
+		query.Set("roomId", source.RoomID)
+	default:
+		return base
+	}
+	return base + "?" + query.Encode()
+}
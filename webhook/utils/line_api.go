@@ -1,19 +1,20 @@
 package utils
 
 import (
-	"bytes"
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"io"
-	"line-chatbot-golang-langchain/models"
+	"line-chatbot-golang-langchain/linebot"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
 	"strings"
+	"sync"
 )
 
 func VerifySignature(signature string, body []byte) bool {
@@ -28,44 +29,35 @@ func VerifySignature(signature string, body []byte) bool {
 	return valid
 }
 
-func ReplyMessage(replyToken string, messages []interface{}) error {
-	body := models.MessageBody{
-		ReplyToken: replyToken,
-		Messages:   messages,
-	}
-
-	log.Println("📤 กำลังส่งข้อความกลับไปยัง LINE Messaging API...")
-	jsonBody, err := json.Marshal(body)
-	if err != nil {
-		log.Println("❌ JSON marshal error:", err)
-		return err
-	}
-
-	req, err := http.NewRequest("POST", "https://api.line.me/v2/bot/message/reply", bytes.NewBuffer(jsonBody))
-	if err != nil {
-		log.Println("❌ Failed to create request:", err)
-		return err
-	}
+var (
+	lineBot     *linebot.Client
+	lineBotOnce sync.Once
+)
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+os.Getenv("LINE_CHANNEL_ACCESS_TOKEN"))
+// LineBot returns the shared Messaging API client, configured from
+// LINE_CHANNEL_ACCESS_TOKEN and the optional LINE_API_BASE_URL.
+func LineBot() *linebot.Client {
+	lineBotOnce.Do(func() {
+		var opts []linebot.Option
+		if baseURL := os.Getenv("LINE_API_BASE_URL"); baseURL != "" {
+			opts = append(opts, linebot.WithBaseURL(baseURL))
+		}
+		lineBot = linebot.New(os.Getenv("LINE_CHANNEL_ACCESS_TOKEN"), opts...)
+	})
+	return lineBot
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// VerifyIDToken returns the LINE user ID a LIFF ID token was issued to.
+func VerifyIDToken(_ context.Context, idToken string) (string, error) {
+	profile, err := GetProfileByIDToken(idToken)
 	if err != nil {
-		log.Println("❌ Request to LINE Messaging API failed:", err)
-		return err
+		return "", err
 	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode >= 400 {
-		body, _ := io.ReadAll(resp.Body)
-		log.Printf("❌ LINE Messaging API returned error. Status: %d Body: %s", resp.StatusCode, string(body))
-		return errors.New("line API error")
+	userID, _ := profile["sub"].(string)
+	if userID == "" {
+		return "", errors.New("ID token profile has no sub")
 	}
-
-	log.Println("✅ ส่งข้อความสำเร็จแล้ว")
-	return nil
+	return userID, nil
 }
 
 func GetProfileByIDToken(idToken string) (map[string]interface{}, error) {
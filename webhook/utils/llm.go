@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"context"
+	"io"
+	"log"
+
+	"line-chatbot-golang-langchain/llm"
+)
+
+// NewLLM creates the configured LLM provider once at startup.
+func NewLLM(ctx context.Context) (llm.LLM, error) {
+	client, err := llm.FromEnv(ctx)
+	if err != nil {
+		log.Println("❌ ไม่สามารถสร้าง LLM client ได้:", err)
+		return nil, err
+	}
+	log.Println("🧠 LLM ready, model:", client.Model())
+	return client, nil
+}
+
+func CloseLLM(client llm.LLM) {
+	if closer, ok := client.(io.Closer); ok {
+		if err := closer.Close(); err != nil {
+			log.Println("⚠️ Error closing LLM client:", err)
+		} else {
+			log.Println("🔒 LLM client ปิดการเชื่อมต่อแล้ว")
+		}
+	}
+}
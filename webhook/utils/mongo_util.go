@@ -6,116 +6,78 @@ import (
 	"os"
 	"time"
 
-	"go.mongodb.org/mongo-driver/v2/bson"
+	"line-chatbot-golang-langchain/repository"
+
 	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
-var client *mongo.Client
-var groupCol *mongo.Collection
-var ctx context.Context
+// Mongo is an open MongoDB connection: the application database and the
+// repositories built on it, for the caller to hand to its handlers and
+// services.
+type Mongo struct {
+	Client *mongo.Client
+	DB     *mongo.Database
+	Repos  *repository.Repositories
+}
 
-func InitMongo() error {
-	var err error
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+// InitMongo connects to MONGO_URI, moves answers left in the groups
+// collection by older versions and creates the repository indexes.
+// The database defaults to "developer"; set MONGO_DATABASE to change it.
+func InitMongo(ctx context.Context) (*Mongo, error) {
+	ctx, cancel := context.WithTimeout(ctx, GetEnvDuration("MONGO_INIT_TIMEOUT", 30*time.Second))
 	defer cancel()
 
 	log.Println("🛠️ Connecting to MongoDB...")
 
 	opt := options.Client().ApplyURI(os.Getenv("MONGO_URI"))
-	client, err = mongo.Connect(opt)
+	client, err := mongo.Connect(opt)
 	if err != nil {
 		log.Println("❌ Failed to connect to MongoDB:", err)
-		return err
+		return nil, err
 	}
+	m := &Mongo{Client: client, DB: client.Database(databaseName())}
 
 	if err := client.Ping(ctx, nil); err != nil {
 		log.Println("❌ MongoDB ping failed:", err)
-		return err
-	}
-
-	groupCol = client.Database("developer").Collection("groups")
-	log.Println("✅ MongoDB connected and 'groups' collection ready.")
-	return nil
-}
-
-func UpsertGroup(groupID string) error {
-	filter := bson.M{"groupId": groupID}
-	update := bson.M{
-		"$set": bson.M{
-			"groupId":   groupID,
-			"updatedAt": time.Now(),
-		},
+		m.Close()
+		return nil, err
 	}
 
-	log.Println("📦 Upserting group:", groupID)
-	opts := options.UpdateOne().SetUpsert(true)
-	_, err := groupCol.UpdateOne(context.Background(), filter, update, opts)
+	repos := repository.New(m.DB)
+	moved, err := repos.MigrateLegacyAssessments(ctx)
 	if err != nil {
-		log.Println("❌ UpsertGroup error:", err)
-	} else {
-		log.Println("✅ UpsertGroup success:", groupID)
+		log.Println("❌ Failed to migrate legacy answers:", err)
+		m.Close()
+		return nil, err
 	}
-	return err
-}
-
-func DeleteGroup(groupID string) error {
-	log.Println("🗑️ Deleting group:", groupID)
-	_, err := groupCol.DeleteOne(context.Background(), bson.M{"groupId": groupID})
-	if err != nil {
-		log.Println("❌ DeleteGroup error:", err)
-	} else {
-		log.Println("✅ Group deleted:", groupID)
+	if moved > 0 {
+		log.Printf("📦 Moved %d legacy answers from groups to assessments", moved)
 	}
-	return err
-}
-
-func UpsertAnswersByUserID(userID, groupID string, data map[string]interface{}) error {
-	filter := bson.M{"userId": userID, "groupId": groupID}
-	data["updatedAt"] = time.Now()
-
-	update := bson.M{"$set": data}
-	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
-
-	log.Printf("📨 Upserting answers for userID=%s, groupID=%s\n", userID, groupID)
-	var updated bson.M
-	err := groupCol.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updated)
-	if err != nil && err != mongo.ErrNoDocuments {
-		log.Println("❌ UpsertAnswers error:", err)
-		return err
+	if err := repos.EnsureIndexes(ctx); err != nil {
+		log.Println("❌ Failed to create indexes:", err)
+		m.Close()
+		return nil, err
 	}
-
-	log.Println("✅ Answers upserted for:", userID)
-	return nil
+	m.Repos = repos
+	log.Printf("✅ MongoDB connected, database %q ready.", m.DB.Name())
+	return m, nil
 }
 
-func GetAnswersByUserID(userID, groupID string) (map[string]interface{}, error) {
-	filter := bson.M{"userId": userID}
-	if groupID != "" {
-		filter["groupId"] = groupID
-	}
-
-	var result map[string]interface{}
-	err := groupCol.FindOne(context.Background(), filter).Decode(&result)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			log.Printf("⚠️ No document found for userId: %s, groupId: %s", userID, groupID)
-			return nil, nil
-		}
-		log.Println("❌ GetAnswersByUserID error:", err)
-		return nil, err
+func databaseName() string {
+	if name := os.Getenv("MONGO_DATABASE"); name != "" {
+		return name
 	}
-
-	return result, nil
+	return "developer"
 }
 
-func CloseMongo() {
-	if client != nil {
-		log.Println("🔌 Closing MongoDB connection...")
-		if err := client.Disconnect(ctx); err != nil {
-			log.Println("❌ Error closing Mongo:", err)
-		} else {
-			log.Println("✅ MongoDB connection closed.")
-		}
+func (m *Mongo) Close() {
+	log.Println("🔌 Closing MongoDB connection...")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := m.Client.Disconnect(ctx); err != nil {
+		log.Println("❌ Error closing Mongo:", err)
+	} else {
+		log.Println("✅ MongoDB connection closed.")
 	}
 }
@@ -0,0 +1,120 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"line-chatbot-golang-langchain/ingest"
+	"line-chatbot-golang-langchain/vectorstore"
+)
+
+var errNotMongo = errors.New("vector indexes only exist for VECTOR_STORE=mongo")
+
+func (kb *Knowledge) VectorGenerations(ctx context.Context) ([]vectorstore.Generation, error) {
+	if kb.DB == nil {
+		return nil, errNotMongo
+	}
+	return kb.generations().List(ctx)
+}
+
+func (kb *Knowledge) indexManager(gen *vectorstore.Generation) *vectorstore.IndexManager {
+	return &vectorstore.IndexManager{
+		Coll:    kb.DB.Collection(gen.Collection),
+		Timeout: GetEnvDuration("VECTOR_INDEX_TIMEOUT", 10*time.Minute),
+	}
+}
+
+// EnsureVectorIndex creates or updates the index of the current generation
+// to fit the embedder and waits until it can be queried.
+func (kb *Knowledge) EnsureVectorIndex(ctx context.Context) (string, error) {
+	gen := kb.Generation
+	if gen == nil {
+		return "", errNotMongo
+	}
+	if err := kb.generations().Register(ctx, *gen); err != nil {
+		return "", err
+	}
+	action, err := kb.indexManager(gen).Ensure(ctx, vectorstore.SpecFor(kb.Embedder, gen.Index))
+	if err != nil {
+		return action, err
+	}
+	log.Printf("✅ Vector index %s on %s %s", gen.Index, gen.Collection, action)
+	return action, nil
+}
+
+// VectorIndexStatus reports the index of a generation; nil means it does
+// not exist.
+func (kb *Knowledge) VectorIndexStatus(ctx context.Context, gen vectorstore.Generation) (*vectorstore.IndexStatus, error) {
+	return kb.indexManager(&gen).Status(ctx, gen.Index)
+}
+
+func (kb *Knowledge) DropVectorIndex(ctx context.Context) error {
+	if kb.Generation == nil {
+		return errNotMongo
+	}
+	return kb.indexManager(kb.Generation).Drop(ctx, kb.Generation.Index)
+}
+
+// SwapVectorGeneration is the blue/green switch to the configured embedding
+// model: the knowledge base is ingested into the model's own generation,
+// its index is built, and only once it is queryable does it become the
+// live generation. The previous one is retired but kept for rollback until
+// it is purged.
+func (kb *Knowledge) SwapVectorGeneration(ctx context.Context, opts IngestOptions) (*ingest.Report, error) {
+	gen := kb.Generation
+	if gen == nil {
+		return nil, errNotMongo
+	}
+	if err := kb.generations().Register(ctx, *gen); err != nil {
+		return nil, err
+	}
+
+	report, err := kb.IngestKnowledge(ctx, opts)
+	if err != nil {
+		return report, err
+	}
+	if report.Failed() > 0 {
+		return report, fmt.Errorf("%d sources failed; generation %s stays %s", report.Failed(), gen.ID, gen.State)
+	}
+	if _, err := kb.EnsureVectorIndex(ctx); err != nil {
+		return report, err
+	}
+	if err := kb.generations().Activate(ctx, gen.ID); err != nil {
+		return report, err
+	}
+	gen.State = vectorstore.GenerationActive
+	log.Printf("🔀 Generation %s (%s) is live", gen.ID, gen.Model)
+	return report, nil
+}
+
+// PurgeVectorGeneration deletes a retired generation's chunks, ledger and
+// index.
+func (kb *Knowledge) PurgeVectorGeneration(ctx context.Context, id string) error {
+	if kb.DB == nil {
+		return errNotMongo
+	}
+	registry := kb.generations()
+	gen, err := registry.Get(ctx, id)
+	if err != nil {
+		return err
+	}
+	if gen == nil {
+		return fmt.Errorf("generation %s is not registered", id)
+	}
+	if gen.State == vectorstore.GenerationActive {
+		return fmt.Errorf("generation %s is live; swap to another one first", id)
+	}
+	if kb.Generation != nil && kb.Generation.ID == id {
+		return fmt.Errorf("generation %s belongs to the configured embedding model", id)
+	}
+	if err := kb.DB.Collection(gen.Collection).Drop(ctx); err != nil {
+		return err
+	}
+	if err := kb.DB.Collection(gen.Ledger).Drop(ctx); err != nil {
+		return err
+	}
+	return registry.Delete(ctx, id)
+}
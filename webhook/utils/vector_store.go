@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+
+	"line-chatbot-golang-langchain/embed"
+	"line-chatbot-golang-langchain/llm"
+	"line-chatbot-golang-langchain/vectorstore"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Knowledge is the knowledge base the bot retrieves from, with the
+// embedder that fills it and the LLM that answers from it.
+type Knowledge struct {
+	// DB is nil for a memory store without the mongo embedding cache.
+	DB       *mongo.Database
+	Embedder embed.Embedder
+	Store    vectorstore.Store
+	// Generation is the generation matching the embedder, when the store
+	// is MongoDB.
+	Generation *vectorstore.Generation
+	// LLM may be nil for tools that only ingest or search.
+	LLM llm.LLM
+}
+
+// OpenKnowledge opens the knowledge base:
+//
+//	VECTOR_STORE       mongo (Atlas Vector Search, default) or memory
+//	VECTOR_STORE_FILE  file a memory store is loaded from and saved to
+func OpenKnowledge(ctx context.Context, db *mongo.Database, embedder embed.Embedder, client llm.LLM) (*Knowledge, error) {
+	kb := &Knowledge{DB: db, Embedder: embedder, LLM: client}
+
+	if os.Getenv("VECTOR_STORE") == "memory" {
+		path := os.Getenv("VECTOR_STORE_FILE")
+		if path == "" {
+			kb.Store = vectorstore.NewMemoryStore(embedder)
+			log.Println("🗂️ Using in-memory vector store")
+			return kb, nil
+		}
+		store, err := vectorstore.LoadMemoryStore(path, embedder)
+		if err != nil {
+			log.Println("❌ Failed to load vector store file:", err)
+			return nil, err
+		}
+		kb.Store = store
+		log.Println("🗂️ Using in-memory vector store from", path)
+		return kb, nil
+	}
+
+	if db == nil {
+		return nil, errors.New("VECTOR_STORE=mongo needs MongoDB")
+	}
+	gen, err := kb.resolveGeneration(ctx)
+	if err != nil {
+		log.Println("❌ Failed to read vector generations:", err)
+		return nil, err
+	}
+	kb.Generation = gen
+	if gen.State != vectorstore.GenerationActive {
+		log.Printf("⚠️ Embedding model %s has no live knowledge base yet (generation %s is %s); run `discctl index swap`", gen.Model, gen.ID, gen.State)
+	}
+	kb.Store = vectorstore.NewMongoStore(db.Collection(gen.Collection), embedder, gen.Index)
+	log.Printf("🗂️ Using MongoDB Atlas vector store %s (generation %s)", gen.Collection, gen.ID)
+	return kb, nil
+}
+
+// resolveGeneration finds the generation built for the embedder. Before
+// any generation is registered, the original collections are used, but
+// only when the vectors already in them have the embedder's dimensions;
+// otherwise the embedder gets a generation of its own that stays inactive
+// until `discctl index swap` fills it.
+func (kb *Knowledge) resolveGeneration(ctx context.Context) (*vectorstore.Generation, error) {
+	registry := kb.generations()
+	gen, err := registry.Get(ctx, vectorstore.GenerationID(kb.Embedder))
+	if err != nil || gen != nil {
+		return gen, err
+	}
+	all, err := registry.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(all) > 0 {
+		fresh := vectorstore.NewGeneration(kb.Embedder, false)
+		return &fresh, nil
+	}
+
+	legacy := vectorstore.NewGeneration(kb.Embedder, true)
+	dims, err := vectorstore.StoredDimensions(ctx, kb.DB.Collection(legacy.Collection))
+	if err != nil {
+		return nil, err
+	}
+	if dims != 0 && dims != kb.Embedder.Dimensions() {
+		log.Printf("⚠️ %s holds %d-dimension vectors but %s makes %d; not using it", legacy.Collection, dims, kb.Embedder.Model(), kb.Embedder.Dimensions())
+		fresh := vectorstore.NewGeneration(kb.Embedder, false)
+		return &fresh, nil
+	}
+	legacy.State = vectorstore.GenerationActive
+	return &legacy, nil
+}
+
+func (kb *Knowledge) generations() *vectorstore.Generations {
+	return vectorstore.NewGenerations(kb.DB.Collection("vector_generations"))
+}
+
+// SaveVectorStore writes a file-backed memory store back to its file. Other
+// stores persist on their own.
+func (kb *Knowledge) SaveVectorStore() error {
+	store, ok := kb.Store.(*vectorstore.MemoryStore)
+	path := os.Getenv("VECTOR_STORE_FILE")
+	if !ok || path == "" {
+		return nil
+	}
+	return store.Save(path)
+}
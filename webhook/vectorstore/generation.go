@@ -0,0 +1,132 @@
+package vectorstore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"line-chatbot-golang-langchain/embed"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// Generation states.
+const (
+	GenerationBuilding = "building"
+	GenerationActive   = "active"
+	GenerationRetired  = "retired"
+)
+
+// Generation is the knowledge base embedded with one model: its own chunk
+// collection, ingestion ledger and vector index. Changing the embedding
+// model builds a new generation next to the live one (blue/green), so bots
+// on the old model keep answering until they are redeployed.
+type Generation struct {
+	ID          string           `bson:"_id"`
+	Collection  string           `bson:"collection"`
+	Ledger      string           `bson:"ledger"`
+	Index       string           `bson:"index"`
+	Model       string           `bson:"model"`
+	Dimensions  int              `bson:"dimensions"`
+	Similarity  embed.Similarity `bson:"similarity"`
+	State       string           `bson:"state"`
+	CreatedAt   time.Time        `bson:"createdAt"`
+	ActivatedAt time.Time        `bson:"activatedAt,omitempty"`
+}
+
+// GenerationID identifies the vectors an embedder produces.
+func GenerationID(e embed.Embedder) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s", e.Model(), e.Dimensions(), e.Similarity())))
+	return hex.EncodeToString(sum[:6])
+}
+
+// NewGeneration names the collections of a generation. The first one keeps
+// the original names so existing deployments carry on unchanged.
+func NewGeneration(e embed.Embedder, first bool) Generation {
+	id := GenerationID(e)
+	gen := Generation{
+		ID:         id,
+		Collection: "disc_embeddings_" + id,
+		Ledger:     "knowledge_sources_" + id,
+		Index:      "vector_index",
+		Model:      e.Model(),
+		Dimensions: e.Dimensions(),
+		Similarity: e.Similarity(),
+		State:      GenerationBuilding,
+		CreatedAt:  time.Now(),
+	}
+	if first {
+		gen.Collection = "disc_embeddings"
+		gen.Ledger = "knowledge_sources"
+	}
+	return gen
+}
+
+// Generations is the registry of generations.
+type Generations struct {
+	coll *mongo.Collection
+}
+
+func NewGenerations(coll *mongo.Collection) *Generations {
+	return &Generations{coll: coll}
+}
+
+// Get returns the generation, or nil when it is not registered.
+func (g *Generations) Get(ctx context.Context, id string) (*Generation, error) {
+	var gen Generation
+	err := g.coll.FindOne(ctx, bson.M{"_id": id}).Decode(&gen)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &gen, nil
+}
+
+func (g *Generations) List(ctx context.Context) ([]Generation, error) {
+	cursor, err := g.coll.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	var gens []Generation
+	if err := cursor.All(ctx, &gens); err != nil {
+		return nil, err
+	}
+	return gens, nil
+}
+
+// Register records a generation unless it already is.
+func (g *Generations) Register(ctx context.Context, gen Generation) error {
+	_, err := g.coll.UpdateOne(ctx, bson.M{"_id": gen.ID}, bson.M{"$setOnInsert": gen}, options.UpdateOne().SetUpsert(true))
+	return err
+}
+
+// Activate makes the generation the live one and retires the previous.
+func (g *Generations) Activate(ctx context.Context, id string) error {
+	if _, err := g.coll.UpdateMany(ctx,
+		bson.M{"_id": bson.M{"$ne": id}, "state": GenerationActive},
+		bson.M{"$set": bson.M{"state": GenerationRetired}},
+	); err != nil {
+		return err
+	}
+	res, err := g.coll.UpdateOne(ctx, bson.M{"_id": id},
+		bson.M{"$set": bson.M{"state": GenerationActive, "activatedAt": time.Now()}})
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return fmt.Errorf("generation %s is not registered", id)
+	}
+	return nil
+}
+
+func (g *Generations) Delete(ctx context.Context, id string) error {
+	_, err := g.coll.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,211 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"line-chatbot-golang-langchain/embed"
+	"line-chatbot-golang-langchain/models"
+
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// DefaultFilterFields are the chunk metadata keys searches can filter on:
+// the source, section, DISC type and language set by ingestion.
+var DefaultFilterFields = []string{MetadataSource, "section", "discType", "language"}
+
+// Outcomes of IndexManager.Ensure.
+const (
+	IndexCreated   = "created"
+	IndexUpdated   = "updated"
+	IndexUnchanged = "unchanged"
+)
+
+var ErrIndexTimeout = errors.New("timed out waiting for the vector index to become queryable")
+
+// IndexSpec describes the Atlas vector index a store needs.
+type IndexSpec struct {
+	Name       string
+	Path       string
+	Dimensions int
+	Similarity embed.Similarity
+	// FilterFields are metadata keys, indexed as metadata.<key>.
+	FilterFields []string
+}
+
+// SpecFor sizes an index for the embedder's vectors.
+func SpecFor(e embed.Embedder, name string) IndexSpec {
+	return IndexSpec{
+		Name:         name,
+		Path:         EmbeddingPath,
+		Dimensions:   e.Dimensions(),
+		Similarity:   e.Similarity(),
+		FilterFields: DefaultFilterFields,
+	}
+}
+
+func (s IndexSpec) Definition() models.VectorDefinition {
+	fields := []models.VectorDefinitionField{{
+		Type:          "vector",
+		Path:          s.Path,
+		NumDimensions: s.Dimensions,
+		Similarity:    string(s.Similarity),
+	}}
+	for _, key := range s.FilterFields {
+		fields = append(fields, models.VectorDefinitionField{Type: "filter", Path: "metadata." + key})
+	}
+	return models.VectorDefinition{Fields: fields}
+}
+
+// IndexStatus is what Atlas reports about an index.
+type IndexStatus struct {
+	Name       string
+	Status     string
+	Queryable  bool
+	Definition models.VectorDefinition
+}
+
+// IndexManager creates, updates and drops the vector index of a collection.
+type IndexManager struct {
+	Coll *mongo.Collection
+	// PollInterval is how often the index is checked while building.
+	PollInterval time.Duration
+	// Timeout bounds the wait for the index to become queryable.
+	Timeout time.Duration
+}
+
+// Ensure makes the index match the spec: it is created when missing,
+// updated in place when its definition differs, and left alone otherwise.
+// It then waits until the index is queryable.
+func (m *IndexManager) Ensure(ctx context.Context, spec IndexSpec) (string, error) {
+	if spec.Dimensions <= 0 {
+		return "", fmt.Errorf("index %s: embedder reports %d dimensions", spec.Name, spec.Dimensions)
+	}
+	current, err := m.Status(ctx, spec.Name)
+	if err != nil {
+		return "", err
+	}
+
+	want := spec.Definition()
+	action := IndexUnchanged
+	switch {
+	case current == nil:
+		opts := options.SearchIndexes().SetName(spec.Name).SetType("vectorSearch")
+		if _, err := m.Coll.SearchIndexes().CreateOne(ctx, mongo.SearchIndexModel{Definition: want, Options: opts}); err != nil {
+			return "", fmt.Errorf("create index %s: %w", spec.Name, err)
+		}
+		action = IndexCreated
+	case !sameDefinition(current.Definition, want):
+		if err := m.Coll.SearchIndexes().UpdateOne(ctx, spec.Name, want); err != nil {
+			return "", fmt.Errorf("update index %s: %w", spec.Name, err)
+		}
+		action = IndexUpdated
+	}
+
+	return action, m.WaitQueryable(ctx, spec)
+}
+
+// Status returns the index as Atlas reports it, or nil when it does not
+// exist.
+func (m *IndexManager) Status(ctx context.Context, name string) (*IndexStatus, error) {
+	cursor, err := m.Coll.SearchIndexes().List(ctx, options.SearchIndexes().SetName(name))
+	if err != nil {
+		return nil, fmt.Errorf("list search indexes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	if !cursor.Next(ctx) {
+		return nil, cursor.Err()
+	}
+	var doc struct {
+		Name             string                  `bson:"name"`
+		Status           string                  `bson:"status"`
+		Queryable        bool                    `bson:"queryable"`
+		LatestDefinition models.VectorDefinition `bson:"latestDefinition"`
+	}
+	if err := cursor.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &IndexStatus{Name: doc.Name, Status: doc.Status, Queryable: doc.Queryable, Definition: doc.LatestDefinition}, nil
+}
+
+// WaitQueryable polls until the index is ready to serve the spec's
+// definition, fails, or the timeout passes. Right after an update Atlas
+// still reports the old definition as READY, so a ready index only counts
+// once its definition matches the spec.
+func (m *IndexManager) WaitQueryable(ctx context.Context, spec IndexSpec) error {
+	name := spec.Name
+	want := spec.Definition()
+	interval := m.PollInterval
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	timeout := m.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		status, err := m.Status(ctx, name)
+		if err != nil && ctx.Err() == nil {
+			return err
+		}
+		if status != nil {
+			if status.Status == "FAILED" {
+				return fmt.Errorf("index %s failed to build", name)
+			}
+			if status.ready(want) {
+				return nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return fmt.Errorf("%w: %s after %s", ErrIndexTimeout, name, timeout)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// ready reports whether the index serves queries with the want definition.
+func (s *IndexStatus) ready(want models.VectorDefinition) bool {
+	return s.Queryable && s.Status == "READY" && sameDefinition(s.Definition, want)
+}
+
+func (m *IndexManager) Drop(ctx context.Context, name string) error {
+	return m.Coll.SearchIndexes().DropOne(ctx, name)
+}
+
+// sameDefinition compares definitions regardless of field order.
+func sameDefinition(a, b models.VectorDefinition) bool {
+	if len(a.Fields) != len(b.Fields) {
+		return false
+	}
+	key := func(f models.VectorDefinitionField) string {
+		return fmt.Sprintf("%s|%s|%d|%s", f.Type, f.Path, f.NumDimensions, f.Similarity)
+	}
+	ka := make([]string, len(a.Fields))
+	kb := make([]string, len(b.Fields))
+	for i := range a.Fields {
+		ka[i], kb[i] = key(a.Fields[i]), key(b.Fields[i])
+	}
+	sort.Strings(ka)
+	sort.Strings(kb)
+	for i := range ka {
+		if ka[i] != kb[i] {
+			return false
+		}
+	}
+	return true
+}
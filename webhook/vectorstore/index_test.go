@@ -0,0 +1,37 @@
+package vectorstore
+
+import (
+	"testing"
+
+	"line-chatbot-golang-langchain/embed"
+	"line-chatbot-golang-langchain/models"
+)
+
+func TestIndexStatusReady(t *testing.T) {
+	spec := IndexSpec{Name: "idx", Path: EmbeddingPath, Dimensions: 768, Similarity: embed.Cosine, FilterFields: []string{"source", "language"}}
+	want := spec.Definition()
+
+	old := spec
+	old.Dimensions = 384
+	reordered := models.VectorDefinition{Fields: []models.VectorDefinitionField{want.Fields[2], want.Fields[0], want.Fields[1]}}
+
+	for _, tt := range []struct {
+		name   string
+		status IndexStatus
+		ready  bool
+	}{
+		{name: "ready", status: IndexStatus{Status: "READY", Queryable: true, Definition: want}, ready: true},
+		{name: "fields in another order", status: IndexStatus{Status: "READY", Queryable: true, Definition: reordered}, ready: true},
+		// Just after UpdateOne the previous definition is still served.
+		{name: "old definition still ready", status: IndexStatus{Status: "READY", Queryable: true, Definition: old.Definition()}},
+		{name: "building", status: IndexStatus{Status: "BUILDING", Queryable: true, Definition: want}},
+		{name: "pending", status: IndexStatus{Status: "PENDING", Definition: want}},
+		{name: "not queryable", status: IndexStatus{Status: "READY", Definition: want}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.status.ready(want); got != tt.ready {
+				t.Errorf("ready = %t, want %t", got, tt.ready)
+			}
+		})
+	}
+}
@@ -0,0 +1,277 @@
+package vectorstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+
+	"line-chatbot-golang-langchain/embed"
+
+	"github.com/tmc/langchaingo/schema"
+)
+
+// MemoryStore is a pure-Go brute-force store for local development and
+// tests. It can be saved to and loaded from a JSON file, so an ingested
+// knowledge base survives restarts without Atlas.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	embedder embed.Embedder
+	entries  []memoryEntry
+	nextID   int
+}
+
+type memoryEntry struct {
+	ID       string         `json:"id"`
+	Content  string         `json:"content"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Vector   []float32      `json:"vector"`
+}
+
+type memoryFile struct {
+	Model      string           `json:"model"`
+	Dimensions int              `json:"dimensions"`
+	Similarity embed.Similarity `json:"similarity"`
+	NextID     int              `json:"nextId"`
+	Entries    []memoryEntry    `json:"entries"`
+}
+
+// NewMemoryStore compares vectors with the embedder's similarity function.
+func NewMemoryStore(embedder embed.Embedder) *MemoryStore {
+	return &MemoryStore{embedder: embedder}
+}
+
+// LoadMemoryStore reads a store saved by Save. A missing file gives an
+// empty store. The file must have been built with the same embedding
+// model, since vectors of different models cannot be compared.
+func LoadMemoryStore(path string, embedder embed.Embedder) (*MemoryStore, error) {
+	store := NewMemoryStore(embedder)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var file memoryFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", path, err)
+	}
+	if file.Model != embedder.Model() || file.Dimensions != embedder.Dimensions() {
+		return nil, fmt.Errorf("%s holds %s vectors (%d dimensions), embedder is %s (%d dimensions)",
+			path, file.Model, file.Dimensions, embedder.Model(), embedder.Dimensions())
+	}
+	if file.Similarity != "" && file.Similarity != embedder.Similarity() {
+		return nil, fmt.Errorf("%s was built for %s similarity, not %s", path, file.Similarity, embedder.Similarity())
+	}
+	store.entries = file.Entries
+	store.nextID = file.NextID
+	return store, nil
+}
+
+// Save writes the store to path, replacing the file atomically.
+func (m *MemoryStore) Save(path string) error {
+	m.mu.RLock()
+	data, err := json.Marshal(memoryFile{
+		Model:      m.embedder.Model(),
+		Dimensions: m.embedder.Dimensions(),
+		Similarity: m.embedder.Similarity(),
+		NextID:     m.nextID,
+		Entries:    m.entries,
+	})
+	m.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (m *MemoryStore) Add(ctx context.Context, docs []schema.Document) ([]string, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.PageContent
+	}
+	vectors, err := m.embedder.EmbedDocuments(ctx, texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(docs) {
+		return nil, fmt.Errorf("embedder returned %d vectors for %d documents", len(vectors), len(docs))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	ids := make([]string, len(docs))
+	for i, doc := range docs {
+		m.nextID++
+		ids[i] = strconv.Itoa(m.nextID)
+		m.entries = append(m.entries, memoryEntry{
+			ID:       ids[i],
+			Content:  doc.PageContent,
+			Metadata: doc.Metadata,
+			Vector:   vectors[i],
+		})
+	}
+	return ids, nil
+}
+
+func (m *MemoryStore) Search(ctx context.Context, query string, k int, opts ...SearchOption) ([]schema.Document, error) {
+	o, err := buildSearchOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	vector, err := m.embedder.EmbedQuery(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var found []schema.Document
+	for _, entry := range m.entries {
+		if !matchesFilter(entry.Metadata, o.Filter) {
+			continue
+		}
+		score := m.score(vector, entry.Vector)
+		if score < o.Threshold {
+			continue
+		}
+		found = append(found, schema.Document{PageContent: entry.Content, Metadata: entry.Metadata, Score: score})
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].Score > found[j].Score })
+	if k > 0 && len(found) > k {
+		found = found[:k]
+	}
+	return found, nil
+}
+
+func (m *MemoryStore) DeleteBySource(_ context.Context, source string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	kept := m.entries[:0]
+	var deleted int64
+	for _, entry := range m.entries {
+		if matchesFilter(entry.Metadata, map[string]any{MetadataSource: source}) {
+			deleted++
+			continue
+		}
+		kept = append(kept, entry)
+	}
+	m.entries = kept
+	return deleted, nil
+}
+
+func (m *MemoryStore) Count(context.Context) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return int64(len(m.entries)), nil
+}
+
+// score normalises the similarity to [0, 1] the way Atlas does.
+func (m *MemoryStore) score(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB, dist float64
+	for i := range a {
+		x, y := float64(a[i]), float64(b[i])
+		dot += x * y
+		normA += x * x
+		normB += y * y
+		dist += (x - y) * (x - y)
+	}
+	switch m.embedder.Similarity() {
+	case embed.Euclidean:
+		return float32(1 / (1 + math.Sqrt(dist)))
+	case embed.DotProduct:
+		return float32((1 + dot) / 2)
+	default:
+		if normA == 0 || normB == 0 {
+			return 0
+		}
+		return float32((1 + dot/(math.Sqrt(normA)*math.Sqrt(normB))) / 2)
+	}
+}
+
+// matchesFilter follows MongoDB's $eq: numbers match by value whatever
+// their Go type, so a chunk number still matches after a save/load round
+// trip turns it into a float64, but a number never matches its text.
+func matchesFilter(metadata, filter map[string]any) bool {
+	for key, want := range filter {
+		got, ok := metadata[key]
+		if !ok || !filterEqual(got, want) {
+			return false
+		}
+	}
+	return true
+}
+
+func filterEqual(a, b any) bool {
+	x, aNum := number(a)
+	y, bNum := number(b)
+	if aNum || bNum {
+		return aNum && bNum && x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// Each calls fn for every stored chunk, in insertion order.
+func (m *MemoryStore) Each(ctx context.Context, fn func(Chunk) error) error {
+	m.mu.RLock()
+	entries := append([]memoryEntry(nil), m.entries...)
+	m.mu.RUnlock()
+
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(Chunk{ID: entry.ID, Content: entry.Content, Metadata: entry.Metadata, Vector: entry.Vector}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
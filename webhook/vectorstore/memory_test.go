@@ -0,0 +1,253 @@
+package vectorstore
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"line-chatbot-golang-langchain/embed"
+
+	"github.com/tmc/langchaingo/schema"
+)
+
+// tableEmbedder looks vectors up by text, so tests can place documents and
+// queries exactly.
+type tableEmbedder struct {
+	model      string
+	similarity embed.Similarity
+	vectors    map[string][]float32
+}
+
+func (e *tableEmbedder) EmbedDocuments(ctx context.Context, texts []string) ([][]float32, error) {
+	out := make([][]float32, len(texts))
+	for i, text := range texts {
+		out[i] = e.vectors[text]
+	}
+	return out, nil
+}
+
+func (e *tableEmbedder) EmbedQuery(ctx context.Context, text string) ([]float32, error) {
+	return e.vectors[text], nil
+}
+
+func (e *tableEmbedder) Model() string                { return e.model }
+func (e *tableEmbedder) Dimensions() int              { return 2 }
+func (e *tableEmbedder) Similarity() embed.Similarity { return e.similarity }
+
+// newTestStore holds three documents around the unit circle; the query
+// points at "near".
+func newTestStore(t *testing.T, similarity embed.Similarity) *MemoryStore {
+	t.Helper()
+	e := &tableEmbedder{
+		model:      "table",
+		similarity: similarity,
+		vectors: map[string][]float32{
+			"query":  {1, 0},
+			"near":   {0.9, 0.1},
+			"middle": {0.5, 0.5},
+			"far":    {-1, 0},
+			"long":   {1.8, 1},
+		},
+	}
+	store := NewMemoryStore(e)
+	docs := []schema.Document{
+		{PageContent: "near", Metadata: map[string]any{MetadataSource: "a.md", "chunk": 1, "discType": "D"}},
+		{PageContent: "middle", Metadata: map[string]any{MetadataSource: "a.md", "chunk": 2, "discType": "I"}},
+		{PageContent: "far", Metadata: map[string]any{MetadataSource: "b.md", "chunk": 1, "discType": "D"}},
+	}
+	if _, err := store.Add(context.Background(), docs); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func contents(docs []schema.Document) []string {
+	out := []string{}
+	for _, doc := range docs {
+		out = append(out, doc.PageContent)
+	}
+	return out
+}
+
+func TestMemoryStoreRanking(t *testing.T) {
+	for _, tt := range []struct {
+		similarity embed.Similarity
+		want       []string
+		top        float32
+	}{
+		{similarity: embed.Cosine, want: []string{"near", "middle", "far"}, top: 0.9969},
+		{similarity: embed.DotProduct, want: []string{"near", "middle", "far"}, top: 0.95},
+		{similarity: embed.Euclidean, want: []string{"near", "middle", "far"}, top: 0.8761},
+	} {
+		t.Run(string(tt.similarity), func(t *testing.T) {
+			got, err := newTestStore(t, tt.similarity).Search(context.Background(), "query", 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(contents(got), tt.want) {
+				t.Errorf("ranking = %v, want %v", contents(got), tt.want)
+			}
+			if d := got[0].Score - tt.top; d > 0.001 || d < -0.001 {
+				t.Errorf("top score = %v, want %v", got[0].Score, tt.top)
+			}
+			for _, doc := range got {
+				if doc.Score < 0 || doc.Score > 1 {
+					t.Errorf("%s scored %v, outside [0, 1]", doc.PageContent, doc.Score)
+				}
+			}
+		})
+	}
+}
+
+// Cosine ignores the length of a vector; Euclidean does not.
+func TestMemoryStoreSimilarityDiffers(t *testing.T) {
+	for _, tt := range []struct {
+		similarity embed.Similarity
+		want       string
+	}{
+		{similarity: embed.Cosine, want: "middle"},
+		{similarity: embed.Euclidean, want: "near"},
+	} {
+		store := newTestStore(t, tt.similarity)
+		got, err := store.Search(context.Background(), "long", 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != 1 || got[0].PageContent != tt.want {
+			t.Errorf("%s: nearest to long = %v, want %s", tt.similarity, contents(got), tt.want)
+		}
+	}
+}
+
+func TestMemoryStoreSearchOptions(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		k     int
+		opts  []SearchOption
+		want  []string
+		fails bool
+	}{
+		{name: "k limits results", k: 2, want: []string{"near", "middle"}},
+		{name: "threshold", opts: []SearchOption{WithThreshold(0.8)}, want: []string{"near", "middle"}},
+		{name: "threshold above every score", opts: []SearchOption{WithThreshold(1)}, want: []string{}},
+		{name: "threshold out of range", opts: []SearchOption{WithThreshold(1.5)}, fails: true},
+		{name: "filter by source", opts: []SearchOption{WithFilter(map[string]any{MetadataSource: "a.md"})}, want: []string{"near", "middle"}},
+		{name: "filters combine", opts: []SearchOption{WithFilter(map[string]any{"discType": "D", "chunk": 1})}, want: []string{"near", "far"}},
+		{name: "number matches by value", opts: []SearchOption{WithFilter(map[string]any{"chunk": 2.0})}, want: []string{"middle"}},
+		{name: "int64 matches int", opts: []SearchOption{WithFilter(map[string]any{"chunk": int64(2)})}, want: []string{"middle"}},
+		{name: "number never matches text", opts: []SearchOption{WithFilter(map[string]any{"chunk": "2"})}, want: []string{}},
+		{name: "text never matches number", opts: []SearchOption{WithFilter(map[string]any{"discType": 1})}, want: []string{}},
+		{name: "missing key", opts: []SearchOption{WithFilter(map[string]any{"language": "th"})}, want: []string{}},
+		{
+			name: "filter and threshold",
+			opts: []SearchOption{WithFilter(map[string]any{"discType": "D"}), WithThreshold(0.5)},
+			want: []string{"near"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newTestStore(t, embed.Cosine).Search(context.Background(), "query", tt.k, tt.opts...)
+			if tt.fails {
+				if err == nil {
+					t.Error("Search succeeded, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(contents(got), tt.want) {
+				t.Errorf("Search = %v, want %v", contents(got), tt.want)
+			}
+		})
+	}
+}
+
+func TestMemoryStoreDeleteBySource(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t, embed.Cosine)
+
+	deleted, err := store.DeleteBySource(ctx, "a.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deleted != 2 {
+		t.Errorf("deleted %d, want 2", deleted)
+	}
+	if n, _ := store.Count(ctx); n != 1 {
+		t.Errorf("Count = %d, want 1", n)
+	}
+	if deleted, _ := store.DeleteBySource(ctx, "a.md"); deleted != 0 {
+		t.Errorf("second delete removed %d", deleted)
+	}
+
+	// IDs keep counting up, so a re-ingested chunk never reuses an ID.
+	ids, err := store.Add(ctx, []schema.Document{{PageContent: "near", Metadata: map[string]any{MetadataSource: "a.md"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"4"}) {
+		t.Errorf("new ID = %v, want [4]", ids)
+	}
+}
+
+func TestMemoryStoreSaveLoad(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t, embed.Cosine)
+	path := filepath.Join(t.TempDir(), "store.json")
+	if err := store.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := LoadMemoryStore(path, store.embedder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := loaded.Count(ctx); n != 3 {
+		t.Fatalf("loaded %d chunks, want 3", n)
+	}
+
+	before, _ := store.Search(ctx, "query", 0)
+	after, err := loaded.Search(ctx, "query", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(contents(after), contents(before)) {
+		t.Errorf("ranking after load = %v, want %v", contents(after), contents(before))
+	}
+	// JSON turns the int chunk number into a float64; filters still match.
+	got, _ := loaded.Search(ctx, "query", 0, WithFilter(map[string]any{"chunk": 2}))
+	if !reflect.DeepEqual(contents(got), []string{"middle"}) {
+		t.Errorf("filter after load = %v, want [middle]", contents(got))
+	}
+	ids, _ := loaded.Add(ctx, []schema.Document{{PageContent: "far"}})
+	if !reflect.DeepEqual(ids, []string{"4"}) {
+		t.Errorf("ID after load = %v, want [4]", ids)
+	}
+}
+
+func TestLoadMemoryStore(t *testing.T) {
+	dir := t.TempDir()
+	cosine := &tableEmbedder{model: "table", similarity: embed.Cosine}
+
+	empty, err := LoadMemoryStore(filepath.Join(dir, "missing.json"), cosine)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := empty.Count(context.Background()); n != 0 {
+		t.Errorf("missing file loaded %d chunks", n)
+	}
+
+	path := filepath.Join(dir, "store.json")
+	if err := newTestStore(t, embed.Cosine).Save(path); err != nil {
+		t.Fatal(err)
+	}
+	for name, e := range map[string]*tableEmbedder{
+		"other model":      {model: "other", similarity: embed.Cosine},
+		"other similarity": {model: "table", similarity: embed.Euclidean},
+	} {
+		if _, err := LoadMemoryStore(path, e); err == nil {
+			t.Errorf("%s: loaded a store built for another embedder", name)
+		}
+	}
+}
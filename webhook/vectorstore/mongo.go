@@ -0,0 +1,127 @@
+package vectorstore
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"line-chatbot-golang-langchain/embed"
+	"line-chatbot-golang-langchain/repository"
+
+	"github.com/tmc/langchaingo/schema"
+	"github.com/tmc/langchaingo/vectorstores"
+	"github.com/tmc/langchaingo/vectorstores/mongovector"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// EmbeddingPath is the field holding each chunk's vector.
+const EmbeddingPath = "embedding"
+
+// MongoStore is the Atlas Vector Search backend. Searches need a vector
+// index on the collection, with every metadata key used in a filter
+// declared as a filter field.
+type MongoStore struct {
+	coll  *mongo.Collection
+	index string
+	store mongovector.Store
+}
+
+func NewMongoStore(coll *mongo.Collection, embedder embed.Embedder, index string) *MongoStore {
+	return &MongoStore{
+		coll:  coll,
+		index: index,
+		store: mongovector.New(coll, embedder, mongovector.WithPath(EmbeddingPath), mongovector.WithIndex(index)),
+	}
+}
+
+func (m *MongoStore) Collection() *mongo.Collection {
+	return m.coll
+}
+
+func (m *MongoStore) Add(ctx context.Context, docs []schema.Document) ([]string, error) {
+	if len(docs) == 0 {
+		return nil, nil
+	}
+	return m.store.AddDocuments(ctx, docs)
+}
+
+func (m *MongoStore) Search(ctx context.Context, query string, k int, opts ...SearchOption) ([]schema.Document, error) {
+	o, err := buildSearchOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	vsOpts := []vectorstores.Option{vectorstores.WithScoreThreshold(o.Threshold)}
+	if len(o.Filter) > 0 {
+		vsOpts = append(vsOpts, vectorstores.WithFilters(mongoFilter(o.Filter)))
+	}
+	return m.store.SimilaritySearch(ctx, query, k, vsOpts...)
+}
+
+func (m *MongoStore) DeleteBySource(ctx context.Context, source string) (int64, error) {
+	res, err := m.coll.DeleteMany(ctx, bson.M{"metadata." + MetadataSource: source})
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
+
+func (m *MongoStore) Count(ctx context.Context) (int64, error) {
+	return m.coll.CountDocuments(ctx, bson.M{})
+}
+
+// StoredDimensions returns the length of a vector stored in coll, or 0
+// when it holds none.
+func StoredDimensions(ctx context.Context, coll *mongo.Collection) (int, error) {
+	var doc struct {
+		Embedding []float64 `bson:"embedding"`
+	}
+	err := coll.FindOne(ctx,
+		bson.M{EmbeddingPath: bson.M{"$exists": true}},
+		options.FindOne().SetProjection(bson.M{EmbeddingPath: 1}),
+	).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return len(doc.Embedding), nil
+}
+
+// mongoFilter turns {"source": "x"} into a $vectorSearch pre-filter on the
+// metadata sub-document.
+func mongoFilter(filter map[string]any) bson.D {
+	keys := make([]string, 0, len(filter))
+	for key := range filter {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	clauses := make(bson.A, 0, len(keys))
+	for _, key := range keys {
+		clauses = append(clauses, bson.D{{Key: "metadata." + key, Value: bson.D{{Key: "$eq", Value: filter[key]}}}})
+	}
+	return bson.D{{Key: "$and", Value: clauses}}
+}
+
+// Each calls fn for every stored chunk.
+func (m *MongoStore) Each(ctx context.Context, fn func(Chunk) error) error {
+	cursor, err := m.coll.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var doc repository.KnowledgeChunk
+		if err := cursor.Decode(&doc); err != nil {
+			return err
+		}
+		if err := fn(Chunk{ID: doc.ID.Hex(), Content: doc.Content, Metadata: doc.Metadata, Vector: doc.Embedding}); err != nil {
+			return err
+		}
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,71 @@
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tmc/langchaingo/schema"
+)
+
+// MetadataSource is the metadata key naming the document a chunk came from.
+const MetadataSource = "source"
+
+// Store holds knowledge chunks and finds the ones closest to a query.
+//
+// Scores follow Atlas Vector Search: similarities are normalised to [0, 1]
+// ((1 + similarity) / 2, or 1 / (1 + distance) for euclidean), so a
+// threshold means the same thing on every backend.
+type Store interface {
+	// Add embeds and stores the documents, returning their IDs.
+	Add(ctx context.Context, docs []schema.Document) ([]string, error)
+	// Search returns up to k documents most similar to the query, best first.
+	Search(ctx context.Context, query string, k int, opts ...SearchOption) ([]schema.Document, error)
+	// DeleteBySource removes every chunk whose metadata source matches.
+	DeleteBySource(ctx context.Context, source string) (int64, error)
+	Count(ctx context.Context) (int64, error)
+}
+
+type SearchOptions struct {
+	// Threshold drops results scoring below it; 0 keeps everything.
+	Threshold float32
+	// Filter keeps only documents whose metadata has all these values.
+	Filter map[string]any
+}
+
+type SearchOption func(*SearchOptions)
+
+func WithThreshold(threshold float32) SearchOption {
+	return func(o *SearchOptions) {
+		o.Threshold = threshold
+	}
+}
+
+func WithFilter(filter map[string]any) SearchOption {
+	return func(o *SearchOptions) {
+		o.Filter = filter
+	}
+}
+
+func buildSearchOptions(opts []SearchOption) (SearchOptions, error) {
+	var o SearchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.Threshold < 0 || o.Threshold > 1 {
+		return o, fmt.Errorf("score threshold %v is outside [0, 1]", o.Threshold)
+	}
+	return o, nil
+}
+
+// Chunk is a stored document together with its vector.
+type Chunk struct {
+	ID       string         `json:"id"`
+	Content  string         `json:"content"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Vector   []float32      `json:"vector,omitempty"`
+}
+
+// Exporter is implemented by stores that can list everything they hold.
+type Exporter interface {
+	Each(ctx context.Context, fn func(Chunk) error) error
+}
@@ -0,0 +1,143 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+)
+
+var (
+	ErrQueueFull  = errors.New("worker queue is full")
+	ErrPoolClosed = errors.New("worker pool is shut down")
+)
+
+// Job is a unit of work run by the pool. The context is cancelled when a
+// shutdown deadline passes before the queue is drained.
+type Job func(ctx context.Context)
+
+// Pool runs jobs on a fixed number of goroutines fed by a bounded queue.
+type Pool struct {
+	jobs   chan Job
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu     sync.RWMutex
+	closed bool
+}
+
+func NewPool(concurrency, queueSize int) *Pool {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Pool{
+		jobs:   make(chan Job, queueSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	p.wg.Add(concurrency)
+	for i := 0; i < concurrency; i++ {
+		go p.run()
+	}
+	log.Printf("🧵 Worker pool started: %d workers, queue size %d", concurrency, queueSize)
+	return p
+}
+
+func (p *Pool) run() {
+	defer p.wg.Done()
+	for job := range p.jobs {
+		p.safeRun(job)
+	}
+}
+
+func (p *Pool) safeRun(job Job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("❌ Worker job panicked:", r)
+		}
+	}()
+	job(p.ctx)
+}
+
+// Submit enqueues a job without blocking. It returns ErrQueueFull when the
+// queue has no room so the caller can apply backpressure.
+func (p *Pool) Submit(job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+
+	select {
+	case p.jobs <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// SubmitAll enqueues every job or none of them. It returns ErrQueueFull
+// without enqueueing anything when the queue has no room for the whole
+// batch, so a caller that asks for a retry never leaves half of it queued.
+// A batch larger than the whole queue could never fit, so it is instead
+// queued job by job, blocking until the workers make room.
+func (p *Pool) SubmitAll(jobs []Job) error {
+	if len(jobs) == 1 {
+		return p.Submit(jobs[0])
+	}
+
+	// Holding the write lock keeps other submitters out, and workers only
+	// free up room, so the capacity checked here is there for every send.
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closed {
+		return ErrPoolClosed
+	}
+	if len(jobs) <= cap(p.jobs) && cap(p.jobs)-len(p.jobs) < len(jobs) {
+		return ErrQueueFull
+	}
+	for _, job := range jobs {
+		p.jobs <- job
+	}
+	return nil
+}
+
+// Pending returns the number of queued jobs not yet picked up by a worker.
+func (p *Pool) Pending() int {
+	return len(p.jobs)
+}
+
+// Shutdown stops accepting jobs and waits for the queued ones to finish.
+// If ctx ends first, running jobs are cancelled and ctx.Err() is returned.
+func (p *Pool) Shutdown(ctx context.Context) error {
+	p.mu.Lock()
+	if !p.closed {
+		p.closed = true
+		close(p.jobs)
+	}
+	p.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		p.cancel()
+		log.Println("✅ Worker pool drained")
+		return nil
+	case <-ctx.Done():
+		p.cancel()
+		log.Printf("⚠️ Worker pool shutdown timed out with %d jobs queued", len(p.jobs))
+		return ctx.Err()
+	}
+}
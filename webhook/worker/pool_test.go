@@ -0,0 +1,166 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// blocked returns a pool whose single worker is held by a job until the
+// returned release func is called, so queued jobs stay in the queue.
+func blocked(t *testing.T, queueSize int) (*Pool, func()) {
+	t.Helper()
+	p := NewPool(1, queueSize)
+	started, release := make(chan struct{}), make(chan struct{})
+	if err := p.Submit(func(ctx context.Context) {
+		close(started)
+		<-release
+	}); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+	var once atomic.Bool
+	return p, func() {
+		if once.CompareAndSwap(false, true) {
+			close(release)
+		}
+	}
+}
+
+func TestSubmitQueueFull(t *testing.T) {
+	p, release := blocked(t, 1)
+	defer p.Shutdown(context.Background())
+	defer release()
+
+	noop := func(ctx context.Context) {}
+	if err := p.Submit(noop); err != nil {
+		t.Fatalf("Submit = %v, want room for one job", err)
+	}
+	if err := p.Submit(noop); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Submit = %v, want ErrQueueFull", err)
+	}
+	if p.Pending() != 1 {
+		t.Errorf("Pending = %d, want 1", p.Pending())
+	}
+}
+
+func TestSubmitAllIsAllOrNone(t *testing.T) {
+	p, release := blocked(t, 3)
+
+	var ran atomic.Int32
+	job := func(ctx context.Context) { ran.Add(1) }
+
+	if err := p.SubmitAll([]Job{job, job}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SubmitAll([]Job{job, job}); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("SubmitAll = %v, want ErrQueueFull", err)
+	}
+	if p.Pending() != 2 {
+		t.Errorf("Pending = %d after a rejected batch, want 2", p.Pending())
+	}
+
+	release()
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran.Load() != 2 {
+		t.Errorf("%d jobs ran, want 2", ran.Load())
+	}
+}
+
+// A batch larger than the whole queue can never fit at once; it is queued
+// as the workers make room instead of being rejected forever.
+func TestSubmitAllLargerThanQueue(t *testing.T) {
+	for _, queueSize := range []int{0, 2} {
+		p := NewPool(2, queueSize)
+		var ran atomic.Int32
+		jobs := make([]Job, 5)
+		for i := range jobs {
+			jobs[i] = func(ctx context.Context) { ran.Add(1) }
+		}
+		if err := p.SubmitAll(jobs); err != nil {
+			t.Fatalf("queue %d: SubmitAll = %v", queueSize, err)
+		}
+		if err := p.Shutdown(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if ran.Load() != 5 {
+			t.Errorf("queue %d: %d jobs ran, want 5", queueSize, ran.Load())
+		}
+	}
+}
+
+func TestSubmitAfterShutdown(t *testing.T) {
+	p := NewPool(1, 1)
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	noop := func(ctx context.Context) {}
+	if err := p.Submit(noop); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Submit = %v, want ErrPoolClosed", err)
+	}
+	if err := p.SubmitAll([]Job{noop, noop}); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("SubmitAll = %v, want ErrPoolClosed", err)
+	}
+}
+
+func TestShutdownDrainsQueue(t *testing.T) {
+	p := NewPool(2, 10)
+	var ran atomic.Int32
+	for i := 0; i < 10; i++ {
+		if err := p.Submit(func(ctx context.Context) {
+			time.Sleep(time.Millisecond)
+			ran.Add(1)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if ran.Load() != 10 {
+		t.Errorf("%d jobs ran before Shutdown returned, want 10", ran.Load())
+	}
+}
+
+func TestShutdownTimeoutCancelsJobs(t *testing.T) {
+	p := NewPool(1, 1)
+	cancelled := make(chan struct{})
+	if err := p.Submit(func(ctx context.Context) {
+		<-ctx.Done()
+		close(cancelled)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := p.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Shutdown = %v, want DeadlineExceeded", err)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("running job was not cancelled")
+	}
+}
+
+func TestPanicDoesNotKillWorker(t *testing.T) {
+	p := NewPool(1, 2)
+	var ran atomic.Bool
+	if err := p.SubmitAll([]Job{
+		func(ctx context.Context) { panic("boom") },
+		func(ctx context.Context) { ran.Store(true) },
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !ran.Load() {
+		t.Error("job after a panic did not run")
+	}
+}